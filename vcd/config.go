@@ -22,6 +22,12 @@ type VCDClient struct {
 	*govcd.VCDClient
 	MaxRetryTimeout int
 	InsecureFlag    bool
+
+	edgeGatewayBatcher *edgeGatewayBatcher
+
+	// locks serialises changes per edge gateway and vApp, use it instead of
+	// the Mutex of govcloudair.VCDClient
+	locks *lockManager
 }
 
 func (c *Config) Client() (*VCDClient, error) {
@@ -32,7 +38,7 @@ func (c *Config) Client() (*VCDClient, error) {
 
 	vcdclient := &VCDClient{
 		govcd.NewVCDClient(*u, c.InsecureFlag, types.ApiVersion),
-		c.MaxRetryTimeout, c.InsecureFlag}
+		c.MaxRetryTimeout, c.InsecureFlag, newEdgeGatewayBatcher(edgeGatewayBatchWindow), newLockManager()}
 	org, vcd, err := vcdclient.Authenticate(c.User, c.Password, c.Org, c.VDC)
 	if err != nil {
 		return nil, fmt.Errorf("Something went wrong: %s", err)
@@ -0,0 +1,261 @@
+package vcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceVcdEdgeGatewayConfig() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVcdEdgeGatewayConfigRead,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			// The EdgeGatewayServiceConfiguration of the edge gateway as JSON,
+			// including the shared secrets of VPN tunnels
+			"config": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func dataSourceVcdEdgeGatewayConfigRead(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Unable to find edge gateway: %#v", err)
+	}
+
+	config, err := flattenEdgeGatewayServiceConfiguration(edgeGatewayServices(edgeGateway))
+	if err != nil {
+		return fmt.Errorf("Error exporting edge gateway services: %#v", err)
+	}
+
+	d.SetId(edgeGateway.HREF)
+	d.Set("config", config)
+
+	return nil
+}
+
+// flattenEdgeGatewayServiceConfiguration returns services as the JSON form
+// of the XML they are sent to vCD as, see xmlToJSONValue. Keys are the XML
+// element and attribute names and are sorted, so the same configuration
+// always gives the same document.
+func flattenEdgeGatewayServiceConfiguration(services *GatewayFeatures) (string, error) {
+	configuration := &EdgeGatewayServiceConfiguration{
+		FirewallService:        services.FirewallService,
+		NatService:             services.NatService,
+		GatewayDhcpService:     services.GatewayDhcpService,
+		GatewayIpsecVpnService: services.GatewayIpsecVpnService,
+		LoadBalancerService:    services.LoadBalancerService,
+		StaticRoutingService:   services.StaticRoutingService,
+	}
+
+	output, err := xml.Marshal(configuration)
+	if err != nil {
+		return "", err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(output))
+	token, err := decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	start, ok := token.(xml.StartElement)
+	if !ok {
+		return "", fmt.Errorf("Unexpected XML token %#v", token)
+	}
+
+	document, err := xmlToJSONValue(decoder, start)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := document.(string); ok {
+		document = map[string]interface{}{}
+	}
+
+	output, err = json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(output), nil
+}
+
+// xmlToJSONValue decodes the element started by start into a value that is
+// marshalled to JSON. Elements holding only text give a string. Other
+// elements give an object with a key per child element, an array when the
+// child is repeated, a key prefixed with "@" per attribute and a "#text" key
+// for text. Namespace declarations are left out.
+func xmlToJSONValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	object := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		object["@"+attr.Name.Local] = attr.Value
+	}
+
+	text := ""
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			value, err := xmlToJSONValue(decoder, token)
+			if err != nil {
+				return nil, err
+			}
+
+			name := token.Name.Local
+			switch existing := object[name].(type) {
+			case nil:
+				object[name] = value
+			case []interface{}:
+				object[name] = append(existing, value)
+			default:
+				object[name] = []interface{}{existing, value}
+			}
+		case xml.CharData:
+			text += string(token)
+		case xml.EndElement:
+			if len(object) == 0 {
+				return text, nil
+			}
+			if strings.TrimSpace(text) != "" {
+				object["#text"] = text
+			}
+			return object, nil
+		}
+	}
+}
+
+// jsonValueToXML encodes value, as given by xmlToJSONValue, as elements named
+// name. Numbers and booleans written by hand are encoded as their text.
+func jsonValueToXML(encoder *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, element := range v {
+			err := jsonValueToXML(encoder, name, element)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, "@") {
+				attrValue, err := jsonTextValue(v[key])
+				if err != nil {
+					return fmt.Errorf("Error in attribute %s of %s: %s", key, name, err)
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: key[1:]}, Value: attrValue})
+			}
+		}
+
+		err := encoder.EncodeToken(start)
+		if err != nil {
+			return err
+		}
+
+		if text, ok := v["#text"]; ok {
+			textValue, err := jsonTextValue(text)
+			if err != nil {
+				return fmt.Errorf("Error in text of %s: %s", name, err)
+			}
+			err = encoder.EncodeToken(xml.CharData(textValue))
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, "@") || key == "#text" {
+				continue
+			}
+			err = jsonValueToXML(encoder, key, v[key])
+			if err != nil {
+				return err
+			}
+		}
+
+		return encoder.EncodeToken(start.End())
+	default:
+		text, err := jsonTextValue(v)
+		if err != nil {
+			return fmt.Errorf("Error in %s: %s", name, err)
+		}
+		return encoder.EncodeElement(text, start)
+	}
+}
+
+// jsonTextValue returns the text of a JSON string, number or boolean.
+func jsonTextValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("Unexpected value %#v", value)
+	}
+}
+
+// expandEdgeGatewayServiceConfiguration parses a document returned by
+// flattenEdgeGatewayServiceConfiguration, by turning it back into XML.
+func expandEdgeGatewayServiceConfiguration(config string) (*EdgeGatewayServiceConfiguration, error) {
+	var document interface{}
+	err := json.Unmarshal([]byte(config), &document)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := document.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("Expected a JSON object")
+	}
+
+	var buffer bytes.Buffer
+	encoder := xml.NewEncoder(&buffer)
+	err = jsonValueToXML(encoder, "EdgeGatewayServiceConfiguration", document)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	configuration := &EdgeGatewayServiceConfiguration{}
+	err = xml.Unmarshal(buffer.Bytes(), configuration)
+	if err != nil {
+		return nil, err
+	}
+	return configuration, nil
+}
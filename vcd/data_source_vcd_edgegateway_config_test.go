@@ -0,0 +1,227 @@
+package vcd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func testEdgeGatewayServices() *GatewayFeatures {
+	return &GatewayFeatures{
+		FirewallService: &FirewallService{
+			IsEnabled:     true,
+			DefaultAction: "drop",
+			FirewallRule: []*FirewallRule{
+				{ID: "1", IsEnabled: true, Description: "ssh", Policy: "allow",
+					Protocols: &FirewallRuleProtocols{TCP: true}, Port: 22, DestinationPortRange: "22",
+					DestinationIP: "10.10.0.5", SourcePort: -1, SourcePortRange: "Any", SourceIP: "Any"},
+				{ID: "2", IsEnabled: true, Description: "gre", Policy: "allow",
+					Protocols: &FirewallRuleProtocols{Other: "gre"}, DestinationIP: "10.10.0.5", SourceIP: "Any"},
+			},
+		},
+		NatService: &types.NatService{
+			IsEnabled: true,
+			NatRule: []*types.NatRule{
+				{ID: "65537", RuleType: "SNAT", IsEnabled: true, GatewayNatRule: &types.GatewayNatRule{
+					Interface:    &types.Reference{HREF: "https://vcd.example.com/api/admin/network/1111", Name: "external"},
+					OriginalIP:   "10.10.0.0/24",
+					TranslatedIP: "203.0.113.10",
+				}},
+			},
+		},
+		GatewayDhcpService: &types.GatewayDhcpService{
+			IsEnabled: true,
+			Pool: []*types.DhcpPoolService{
+				{IsEnabled: true, Network: &types.Reference{HREF: "https://vcd.example.com/api/admin/network/2222", Name: "my-net"},
+					DefaultLeaseTime: 3600, MaxLeaseTime: 7200, LowIPAddress: "10.10.0.100", HighIPAddress: "10.10.0.150"},
+			},
+		},
+		GatewayIpsecVpnService: &GatewayIpsecVpnService{
+			IsEnabled: true,
+			Tunnel: []*GatewayIpsecVpnTunnel{
+				{Name: "tunnel", SharedSecret: "secret", Mtu: 1500, IsEnabled: true},
+			},
+		},
+	}
+}
+
+func TestFlattenEdgeGatewayServiceConfiguration(t *testing.T) {
+	config, err := flattenEdgeGatewayServiceConfiguration(testEdgeGatewayServices())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document map[string]interface{}
+	err = json.Unmarshal([]byte(config), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys are the XML names, repeated elements are arrays
+	rules := document["FirewallService"].(map[string]interface{})["FirewallRule"].([]interface{})
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 firewall rules, got %#v", rules)
+	}
+	ssh := rules[0].(map[string]interface{})
+	if ssh["Id"] != "1" || ssh["DestinationIp"] != "10.10.0.5" || ssh["Port"] != "22" {
+		t.Errorf("Expected the XML names and values of the rule, got %#v", ssh)
+	}
+
+	// Attributes are prefixed with @ and single elements are objects
+	pool := document["GatewayDhcpService"].(map[string]interface{})["Pool"].(map[string]interface{})
+	network := pool["Network"].(map[string]interface{})
+	if network["@href"] != "https://vcd.example.com/api/admin/network/2222" || network["@name"] != "my-net" {
+		t.Errorf("Expected the attributes of the network reference, got %#v", network)
+	}
+
+	if _, ok := document["LoadBalancerService"]; ok {
+		t.Errorf("Expected services that aren't set to be left out")
+	}
+
+	again, err := flattenEdgeGatewayServiceConfiguration(testEdgeGatewayServices())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != config {
+		t.Errorf("Expected the same document for the same configuration")
+	}
+}
+
+func TestExpandEdgeGatewayServiceConfiguration(t *testing.T) {
+	services := testEdgeGatewayServices()
+
+	config, err := flattenEdgeGatewayServiceConfiguration(services)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configuration, err := expandEdgeGatewayServiceConfiguration(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := xml.Marshal(&EdgeGatewayServiceConfiguration{
+		FirewallService:        services.FirewallService,
+		NatService:             services.NatService,
+		GatewayDhcpService:     services.GatewayDhcpService,
+		GatewayIpsecVpnService: services.GatewayIpsecVpnService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := xml.Marshal(configuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != string(expected) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestExpandEdgeGatewayServiceConfigurationByHand(t *testing.T) {
+	config := `{
+  "FirewallService": {
+    "IsEnabled": true,
+    "DefaultAction": "drop",
+    "FirewallRule": {
+      "Id": 1,
+      "Description": "ssh",
+      "Protocols": {"Tcp": true},
+      "Port": 22
+    }
+  },
+  "StaticRoutingService": {
+    "IsEnabled": true
+  }
+}`
+
+	configuration, err := expandEdgeGatewayServiceConfiguration(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &FirewallService{
+		IsEnabled:     true,
+		DefaultAction: "drop",
+		FirewallRule: []*FirewallRule{
+			{ID: "1", Description: "ssh", Protocols: &FirewallRuleProtocols{TCP: true}, Port: 22},
+		},
+	}
+	if !reflect.DeepEqual(configuration.FirewallService, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, configuration.FirewallService)
+	}
+	if configuration.StaticRoutingService == nil || !configuration.StaticRoutingService.IsEnabled {
+		t.Errorf("Expected an enabled static routing service, got %#v", configuration.StaticRoutingService)
+	}
+	if configuration.NatService != nil {
+		t.Errorf("Expected no NAT service, got %#v", configuration.NatService)
+	}
+
+	for _, invalid := range []string{`[]`, `{"FirewallService": {"IsEnabled": {"@on": ["yes"]}}}`} {
+		if _, err := expandEdgeGatewayServiceConfiguration(invalid); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
+
+func testAccCheckVcdEdgeGatewayConfigContains(n, text string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if !strings.Contains(rs.Primary.Attributes["config"], text) {
+			return fmt.Errorf("Expected the config of %s to contain %s", n, text)
+		}
+
+		return nil
+	}
+}
+
+func TestAccVcdEdgeGatewayConfig_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdFirewallRuleDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayConfig_basic, edgeGateway, edgeGateway),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"data.vcd_edgegateway_config.test", "config"),
+					testAccCheckVcdEdgeGatewayConfigContains(
+						"data.vcd_edgegateway_config.test", "terraform-acc-config"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdEdgeGatewayConfig_basic = `
+resource "vcd_firewall_rule" "test" {
+  edge_gateway     = "%s"
+  description      = "terraform-acc-config"
+  policy           = "allow"
+  protocol         = "tcp"
+  destination_port = "22"
+  destination_ip   = "10.10.102.50"
+  source_port      = "any"
+  source_ip        = "any"
+}
+
+data "vcd_edgegateway_config" "test" {
+  edge_gateway = "%s"
+  depends_on   = ["vcd_firewall_rule.test"]
+}
+`
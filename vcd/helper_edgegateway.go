@@ -0,0 +1,217 @@
+package vcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/vCloud/govcloudair"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+// edgeGatewayServicesUpdateFunc returns the services to reconfigure, given the
+// edge gateway and its current services. Services left nil in the result
+// are not touched.
+type edgeGatewayServicesUpdateFunc func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error)
+
+// updateEdgeGatewayServices reconfigures the services of the named edge
+// gateway. update is called with a freshly read gateway on every attempt, so
+// changes made in the meantime by others aren't overwritten. Updates made at
+// the same time by other resources are applied in the same configureServices
+// call, see edgeGatewayBatcher. The returned gateway is read after the call.
+func updateEdgeGatewayServices(edgeGatewayName string, update edgeGatewayServicesUpdateFunc, meta interface{}) (*EdgeGateway, error) {
+	vcdClient := meta.(*VCDClient)
+
+	return vcdClient.edgeGatewayBatcher.submit(edgeGatewayName, update, func(changes []*edgeGatewayChange) {
+		applyEdgeGatewayChanges(edgeGatewayName, changes, meta)
+	})
+}
+
+// getEdgeGateway reads the named edge gateway, see EdgeGateway.
+func getEdgeGateway(edgeGatewayName string, meta interface{}) (*EdgeGateway, error) {
+	vcdClient := meta.(*VCDClient)
+
+	found, err := vcdClient.OrgVdc.FindEdgeGateway(edgeGatewayName)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeGateway := &EdgeGateway{}
+	err = getEntity(found.EdgeGateway.HREF, edgeGateway, &vcdClient.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	return edgeGateway, nil
+}
+
+// configureEdgeGatewayServices posts configuration to the configureServices
+// action of the edge gateway found at href. Unlike the govcloudair helpers
+// it returns vCD errors as *types.Error, so busy gateways can be retried.
+func configureEdgeGatewayServices(href string, configuration *EdgeGatewayServiceConfiguration, meta interface{}) (govcloudair.Task, error) {
+	vcdClient := meta.(*VCDClient)
+
+	configuration.Xmlns = "http://www.vmware.com/vcloud/v1.5"
+
+	output, err := xml.MarshalIndent(configuration, "  ", "    ")
+	if err != nil {
+		return govcloudair.Task{}, fmt.Errorf("Error marshaling edge gateway services: %s", err)
+	}
+
+	log.Printf("[DEBUG] Reconfiguring edge gateway services of %s", href)
+
+	return executeRequestWithBody(string(output),
+		href+"/action/configureServices",
+		"POST",
+		"application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml",
+		&vcdClient.Client)
+}
+
+// edgeGatewayServices returns the services of the edge gateway, never nil.
+func edgeGatewayServices(edgeGateway *EdgeGateway) *GatewayFeatures {
+	if edgeGateway.Configuration == nil || edgeGateway.Configuration.EdgeGatewayServiceConfiguration == nil {
+		return &GatewayFeatures{}
+	}
+	return edgeGateway.Configuration.EdgeGatewayServiceConfiguration
+}
+
+// updateNatRule changes the NAT rule with the given Id of the named edge
+// gateway in place. update is called with a copy of the current rule.
+func updateNatRule(edgeGatewayName, id string, update func(rule *types.NatRule), meta interface{}) error {
+	_, err := updateEdgeGatewayServices(edgeGatewayName, func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		existing := findNatRule(edgeGatewayNatRules(edgeGateway), id)
+		if existing == nil || existing.GatewayNatRule == nil {
+			return nil, fmt.Errorf("Unable to find NAT rule %s", id)
+		}
+
+		rule := *existing
+		gatewayNatRule := *existing.GatewayNatRule
+		rule.GatewayNatRule = &gatewayNatRule
+		update(&rule)
+
+		return &EdgeGatewayServiceConfiguration{
+			NatService: replaceNatRules(services.NatService, id, &rule),
+		}, nil
+	}, meta)
+	return err
+}
+
+// removeNatRule removes the NAT rule with the given Id from the named edge
+// gateway, whatever interface it is bound to. Nothing is sent when the rule
+// is already gone.
+func removeNatRule(edgeGatewayName, id string, meta interface{}) error {
+	_, err := updateEdgeGatewayServices(edgeGatewayName, natRuleRemoval(id), meta)
+	return err
+}
+
+// addNatMapping adds rule on the uplink interface of the named edge gateway
+// and returns its Id. Like govcloudair's AddNATPortMapping, an existing rule
+// of the uplink interface that matches is replaced.
+func addNatMapping(edgeGatewayName string, rule *types.NatRule, match func(*types.NatRule) bool, meta interface{}) (string, error) {
+	// sent ends up with the services as sent to vCD, see
+	// resourceVcdFirewallRuleCreate
+	var sent *GatewayFeatures
+	edgeGateway, err := updateEdgeGatewayServices(edgeGatewayName, func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		sent = services
+
+		uplink, err := findUplinkInterfaceNetwork(edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+		rule.GatewayNatRule.Interface = &types.Reference{HREF: uplink.HREF}
+
+		service := &types.NatService{IsEnabled: true}
+		if services.NatService != nil {
+			*service = *services.NatService
+			service.NatRule = nil
+		}
+
+		for _, existing := range natRulesOf(services.NatService) {
+			if match(existing) && existing.GatewayNatRule.Interface != nil &&
+				existing.GatewayNatRule.Interface.HREF == uplink.HREF {
+				continue
+			}
+			service.NatRule = append(service.NatRule, existing)
+		}
+		service.NatRule = append(service.NatRule, rule)
+
+		return &EdgeGatewayServiceConfiguration{
+			NatService: service,
+		}, nil
+	}, meta)
+	if err != nil {
+		return "", err
+	}
+
+	return findNewNatRuleID(edgeGatewayNatRules(edgeGateway), natRulesOf(sent.NatService), rule)
+}
+
+// updateEdgeGatewayConfiguration replaces the edge gateway found at href with
+// the result of update, which is called with a freshly read gateway on every
+// attempt. Services aren't sent, they are changed through configureServices.
+func updateEdgeGatewayConfiguration(href string, update func(edgeGateway *EdgeGateway) error, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(href)
+	defer vcdClient.locks.unlock(href)
+
+	return retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+		edgeGateway := &EdgeGateway{}
+		err := getEntity(href, edgeGateway, &vcdClient.Client)
+		if err != nil {
+			return govcloudair.Task{}, err
+		}
+
+		err = update(edgeGateway)
+		if err != nil {
+			return govcloudair.Task{}, err
+		}
+
+		edgeGateway.Xmlns = "http://www.vmware.com/vcloud/v1.5"
+		edgeGateway.Link = nil
+		edgeGateway.Tasks = nil
+		if edgeGateway.Configuration != nil {
+			edgeGateway.Configuration.EdgeGatewayServiceConfiguration = nil
+		}
+
+		output, err := xml.MarshalIndent(edgeGateway, "  ", "    ")
+		if err != nil {
+			return govcloudair.Task{}, fmt.Errorf("Error marshaling edge gateway: %s", err)
+		}
+
+		log.Printf("[DEBUG] Updating edge gateway %s", href)
+
+		return executeRequestWithBody(string(output),
+			href,
+			"PUT",
+			"application/vnd.vmware.admin.edgeGateway+xml",
+			&vcdClient.Client)
+	})
+}
+
+// runEdgeGatewayAction runs an action without parameters, such as redeploy,
+// on the edge gateway found at href and waits for it to complete.
+func runEdgeGatewayAction(href, action string, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(href)
+	defer vcdClient.locks.unlock(href)
+
+	return retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+		s, err := url.ParseRequestURI(href + "/action/" + action)
+		if err != nil {
+			return govcloudair.Task{}, fmt.Errorf("Error parsing edge gateway href %s: %s", href, err)
+		}
+
+		log.Printf("[DEBUG] Running action %s of edge gateway %s", action, href)
+
+		req := vcdClient.Client.NewRequest(map[string]string{}, "POST", *s, nil)
+
+		task := govcloudair.NewTask(&vcdClient.Client)
+		err = doRequest(&vcdClient.Client, req, task.Task)
+		if err != nil {
+			return govcloudair.Task{}, err
+		}
+
+		return *task, nil
+	})
+}
@@ -0,0 +1,212 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vCloud/govcloudair"
+)
+
+// edgeGatewayBatchWindow is how long the first change to an idle edge gateway
+// waits for the changes other resources make at the same time.
+const edgeGatewayBatchWindow = time.Second
+
+// edgeGatewayBatcher coalesces the service changes that resources make to the
+// same edge gateway at the same time. Every configureServices call redeploys
+// the edge configuration, so changes queued within the window of an idle
+// gateway, or while a call is running, are all applied by the next call.
+type edgeGatewayBatcher struct {
+	mu      sync.Mutex
+	pending map[string][]*edgeGatewayChange
+	running map[string]bool
+
+	window time.Duration
+}
+
+// edgeGatewayChange is a change queued by one resource, with its result.
+type edgeGatewayChange struct {
+	update edgeGatewayServicesUpdateFunc
+
+	// wake is closed when the change has been applied, or when the change
+	// has been picked to apply the next batch, in which case lead is set.
+	wake chan struct{}
+	lead bool
+
+	edgeGateway *EdgeGateway
+	err         error
+}
+
+func newEdgeGatewayBatcher(window time.Duration) *edgeGatewayBatcher {
+	return &edgeGatewayBatcher{
+		pending: make(map[string][]*edgeGatewayChange),
+		running: make(map[string]bool),
+		window:  window,
+	}
+}
+
+// submit queues update for the edge gateway with the given name and waits
+// until it has been applied. When no batch of the gateway is running, the
+// caller waits for the window, applies all queued changes with apply, then
+// hands over to the first change queued in the meantime. That change applies
+// at once, its batch has been collecting while the previous one ran.
+func (b *edgeGatewayBatcher) submit(name string, update edgeGatewayServicesUpdateFunc, apply func(changes []*edgeGatewayChange)) (*EdgeGateway, error) {
+	change := &edgeGatewayChange{update: update, wake: make(chan struct{})}
+
+	b.mu.Lock()
+	b.pending[name] = append(b.pending[name], change)
+	leader := !b.running[name]
+	b.running[name] = true
+	b.mu.Unlock()
+
+	if leader {
+		time.Sleep(b.window)
+	} else {
+		<-change.wake
+		if !change.lead {
+			return change.edgeGateway, change.err
+		}
+	}
+
+	b.mu.Lock()
+	changes := b.pending[name]
+	delete(b.pending, name)
+	b.mu.Unlock()
+
+	log.Printf("[DEBUG] Applying %d changes to edge gateway %s", len(changes), name)
+	apply(changes)
+
+	b.mu.Lock()
+	if next := b.pending[name]; len(next) > 0 {
+		next[0].lead = true
+		close(next[0].wake)
+	} else {
+		delete(b.running, name)
+	}
+	b.mu.Unlock()
+
+	for _, other := range changes {
+		if other != change {
+			close(other.wake)
+		}
+	}
+
+	return change.edgeGateway, change.err
+}
+
+// applyEdgeGatewayChanges applies changes to the named edge gateway in one
+// configureServices call and records the result in each change. A change
+// whose update fails gets its own error and is left out of the call.
+func applyEdgeGatewayChanges(edgeGatewayName string, changes []*edgeGatewayChange, meta interface{}) {
+	vcdClient := meta.(*VCDClient)
+
+	found, err := vcdClient.OrgVdc.FindEdgeGateway(edgeGatewayName)
+	if err != nil {
+		for _, change := range changes {
+			change.err = fmt.Errorf("Unable to find edge gateway: %#v", err)
+		}
+		return
+	}
+	href := found.EdgeGateway.HREF
+
+	// Multiple VCD components need to run operations on the Edge Gateway, as
+	// the edge gatway will throw back an error if it is already performing an
+	// operation we must wait until we can aquire a lock on the gateway
+	vcdClient.locks.lock(href)
+	defer vcdClient.locks.unlock(href)
+
+	var current *EdgeGateway
+	nothingToApply := false
+	err = retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+		current = &EdgeGateway{}
+		err := getEntity(href, current, &vcdClient.Client)
+		if err != nil {
+			return govcloudair.Task{}, fmt.Errorf("Error refreshing edge gateway: %#v", err)
+		}
+
+		configuration, applied := collectEdgeGatewayChanges(current, changes)
+		if applied == 0 {
+			nothingToApply = true
+			return govcloudair.Task{}, fmt.Errorf("No changes to apply")
+		}
+
+		return configureEdgeGatewayServices(href, configuration, meta)
+	})
+	if nothingToApply {
+		// Nothing was sent, the gateway as read is still current
+		finishEdgeGatewayChanges(changes, current, nil)
+		return
+	}
+
+	// Every resource reads its part back from the same, current, gateway
+	edgeGateway := &EdgeGateway{}
+	if err == nil {
+		err = getEntity(href, edgeGateway, &vcdClient.Client)
+		if err != nil {
+			err = fmt.Errorf("Error refreshing edge gateway: %#v", err)
+		}
+	}
+
+	finishEdgeGatewayChanges(changes, edgeGateway, err)
+}
+
+// finishEdgeGatewayChanges records edgeGateway and err as the result of the
+// changes whose update didn't fail.
+func finishEdgeGatewayChanges(changes []*edgeGatewayChange, edgeGateway *EdgeGateway, err error) {
+	for _, change := range changes {
+		if change.err == nil {
+			change.edgeGateway = edgeGateway
+			change.err = err
+		}
+	}
+}
+
+// collectEdgeGatewayChanges calls the update of every change in turn and
+// returns the services they changed, and how many succeeded. Each update sees
+// the gateway and services as left by the previous ones.
+func collectEdgeGatewayChanges(edgeGateway *EdgeGateway, changes []*edgeGatewayChange) (*EdgeGatewayServiceConfiguration, int) {
+	services := &GatewayFeatures{}
+	*services = *edgeGatewayServices(edgeGateway)
+	if edgeGateway.Configuration != nil {
+		edgeGateway.Configuration.EdgeGatewayServiceConfiguration = services
+	}
+
+	configuration := &EdgeGatewayServiceConfiguration{}
+	applied := 0
+	for _, change := range changes {
+		result, err := change.update(edgeGateway, services)
+		change.err = err
+		if err != nil || result == nil {
+			continue
+		}
+
+		if result.FirewallService != nil {
+			services.FirewallService = result.FirewallService
+			configuration.FirewallService = result.FirewallService
+		}
+		if result.NatService != nil {
+			services.NatService = result.NatService
+			configuration.NatService = result.NatService
+		}
+		if result.GatewayDhcpService != nil {
+			services.GatewayDhcpService = result.GatewayDhcpService
+			configuration.GatewayDhcpService = result.GatewayDhcpService
+		}
+		if result.GatewayIpsecVpnService != nil {
+			services.GatewayIpsecVpnService = result.GatewayIpsecVpnService
+			configuration.GatewayIpsecVpnService = result.GatewayIpsecVpnService
+		}
+		if result.LoadBalancerService != nil {
+			services.LoadBalancerService = result.LoadBalancerService
+			configuration.LoadBalancerService = result.LoadBalancerService
+		}
+		if result.StaticRoutingService != nil {
+			services.StaticRoutingService = result.StaticRoutingService
+			configuration.StaticRoutingService = result.StaticRoutingService
+		}
+		applied++
+	}
+
+	return configuration, applied
+}
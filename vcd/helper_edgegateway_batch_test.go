@@ -0,0 +1,231 @@
+package vcd
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+// fakeApply records the changes of every batch and answers each change with
+// an edge gateway named after the batch.
+type fakeApply struct {
+	mu      sync.Mutex
+	batches [][]*edgeGatewayChange
+
+	// release, when set, is waited for before the first batch is applied
+	release chan struct{}
+}
+
+func (f *fakeApply) apply(changes []*edgeGatewayChange) {
+	f.mu.Lock()
+	f.batches = append(f.batches, changes)
+	batch := len(f.batches)
+	f.mu.Unlock()
+
+	if batch == 1 && f.release != nil {
+		<-f.release
+	}
+
+	for _, change := range changes {
+		change.edgeGateway = &EdgeGateway{Name: fmt.Sprintf("batch %d", batch)}
+	}
+}
+
+func noUpdate(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+	return nil, nil
+}
+
+// waitPending waits until count changes are queued for the named gateway.
+func waitPending(t *testing.T, b *edgeGatewayBatcher, name string, count int) {
+	for i := 0; i < 1000; i++ {
+		b.mu.Lock()
+		pending := len(b.pending[name])
+		b.mu.Unlock()
+		if pending == count {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d pending changes", count)
+}
+
+// waitBatches waits until count batches have been started.
+func waitBatches(t *testing.T, f *fakeApply, count int) {
+	for i := 0; i < 1000; i++ {
+		f.mu.Lock()
+		started := len(f.batches)
+		f.mu.Unlock()
+		if started == count {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d batches", count)
+}
+
+func TestEdgeGatewayBatcherSingle(t *testing.T) {
+	b := newEdgeGatewayBatcher(0)
+	f := &fakeApply{}
+
+	edgeGateway, err := b.submit("gw", noUpdate, f.apply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edgeGateway == nil || edgeGateway.Name != "batch 1" {
+		t.Errorf("Expected the edge gateway of batch 1, got %#v", edgeGateway)
+	}
+	if len(f.batches) != 1 || len(f.batches[0]) != 1 {
+		t.Errorf("Expected one batch of one change, got %#v", f.batches)
+	}
+	if len(b.running) != 0 || len(b.pending) != 0 {
+		t.Errorf("Expected the batcher to be idle, got %#v and %#v", b.running, b.pending)
+	}
+}
+
+func TestEdgeGatewayBatcherQueued(t *testing.T) {
+	b := newEdgeGatewayBatcher(0)
+	f := &fakeApply{release: make(chan struct{})}
+
+	results := make([]string, 3)
+	var wg sync.WaitGroup
+	submit := func(i int) {
+		defer wg.Done()
+		edgeGateway, err := b.submit("gw", noUpdate, f.apply)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		results[i] = edgeGateway.Name
+	}
+
+	wg.Add(1)
+	go submit(0)
+	waitBatches(t, f, 1)
+
+	// Both are queued while the first batch is running
+	wg.Add(2)
+	go submit(1)
+	go submit(2)
+	waitPending(t, b, "gw", 2)
+
+	close(f.release)
+	wg.Wait()
+
+	expected := []string{"batch 1", "batch 2", "batch 2"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, results)
+	}
+	if len(f.batches) != 2 || len(f.batches[0]) != 1 || len(f.batches[1]) != 2 {
+		t.Fatalf("Expected batches of 1 and 2 changes, got %#v", f.batches)
+	}
+
+	// The first change queued meanwhile led the second batch
+	if !f.batches[1][0].lead || f.batches[1][1].lead {
+		t.Errorf("Expected the first queued change to lead the second batch")
+	}
+	if len(b.running) != 0 || len(b.pending) != 0 {
+		t.Errorf("Expected the batcher to be idle, got %#v and %#v", b.running, b.pending)
+	}
+}
+
+func TestEdgeGatewayBatcherWindow(t *testing.T) {
+	b := newEdgeGatewayBatcher(200 * time.Millisecond)
+	f := &fakeApply{}
+
+	// All are submitted at once to the idle gateway, within the window
+	const count = 10
+	results := make([]string, count)
+	var wg sync.WaitGroup
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		go func(i int) {
+			defer wg.Done()
+			edgeGateway, err := b.submit("gw", noUpdate, f.apply)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = edgeGateway.Name
+		}(i)
+	}
+	wg.Wait()
+
+	if len(f.batches) != 1 || len(f.batches[0]) != count {
+		t.Fatalf("Expected one batch of %d changes, got %d batches", count, len(f.batches))
+	}
+	for i, name := range results {
+		if name != "batch 1" {
+			t.Errorf("Expected change %d to get the edge gateway of batch 1, got %q", i, name)
+		}
+	}
+	if len(b.running) != 0 || len(b.pending) != 0 {
+		t.Errorf("Expected the batcher to be idle, got %#v and %#v", b.running, b.pending)
+	}
+}
+
+func TestCollectEdgeGatewayChanges(t *testing.T) {
+	route := &types.StaticRoute{Name: "route"}
+	rule := &types.NatRule{ID: "65537"}
+
+	var seenRoutes *StaticRoutingService
+	changes := []*edgeGatewayChange{
+		{update: func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+			return &EdgeGatewayServiceConfiguration{
+				StaticRoutingService: replaceStaticRoute(services.StaticRoutingService, route.Name, route),
+			}, nil
+		}},
+		{update: func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+			return nil, fmt.Errorf("failed")
+		}},
+		{update: noUpdate},
+		{update: func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+			seenRoutes = services.StaticRoutingService
+			return &EdgeGatewayServiceConfiguration{
+				NatService: replaceNatRules(services.NatService, "", rule),
+			}, nil
+		}},
+	}
+
+	edgeGateway := &EdgeGateway{Configuration: &GatewayConfiguration{EdgeGatewayServiceConfiguration: &GatewayFeatures{}}}
+	configuration, applied := collectEdgeGatewayChanges(edgeGateway, changes)
+
+	if applied != 2 {
+		t.Errorf("Expected 2 applied changes, got %d", applied)
+	}
+	if changes[0].err != nil || changes[1].err == nil || changes[2].err != nil || changes[3].err != nil {
+		t.Errorf("Expected only the second change to fail")
+	}
+	if seenRoutes == nil || !reflect.DeepEqual(seenRoutes.StaticRoute, []*types.StaticRoute{route}) {
+		t.Errorf("Expected the last change to see the route of the first, got %#v", seenRoutes)
+	}
+	if configuration.StaticRoutingService == nil || configuration.NatService == nil ||
+		configuration.FirewallService != nil || configuration.GatewayDhcpService != nil {
+		t.Errorf("Expected the static routing and NAT service only, got %#v", configuration)
+	}
+}
+
+func TestFinishEdgeGatewayChanges(t *testing.T) {
+	edgeGateway := &EdgeGateway{Name: "gw"}
+	failed := fmt.Errorf("failed")
+	changes := []*edgeGatewayChange{{}, {err: failed}}
+
+	// Nothing to apply, the changes without error get the gateway as read
+	finishEdgeGatewayChanges(changes, edgeGateway, nil)
+	if changes[0].edgeGateway != edgeGateway || changes[0].err != nil {
+		t.Errorf("Expected the edge gateway without error, got %#v and %#v", changes[0].edgeGateway, changes[0].err)
+	}
+	if changes[1].edgeGateway != nil || changes[1].err != failed {
+		t.Errorf("Expected the failed change to keep its error, got %#v", changes[1].err)
+	}
+
+	configureError := fmt.Errorf("busy")
+	changes = []*edgeGatewayChange{{}}
+	finishEdgeGatewayChanges(changes, edgeGateway, configureError)
+	if changes[0].err != configureError {
+		t.Errorf("Expected the error of the call, got %#v", changes[0].err)
+	}
+}
@@ -0,0 +1,51 @@
+package vcd
+
+import (
+	"sync"
+)
+
+// lockManager hands out one lock per key, the HREF of the object being
+// changed, such as an edge gateway or a vApp. Changes to the same object are
+// serialised while changes to independent objects run in parallel.
+type lockManager struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the lock of a key, with the number of callers holding or
+// waiting for it, so it can be dropped once unused.
+type keyLock struct {
+	sync.Mutex
+	users int
+}
+
+func newLockManager() *lockManager {
+	return &lockManager{locks: make(map[string]*keyLock)}
+}
+
+// lock waits until the lock of key is free and takes it.
+func (m *lockManager) lock(key string) {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyLock{}
+		m.locks[key] = l
+	}
+	l.users++
+	m.mu.Unlock()
+
+	l.Lock()
+}
+
+// unlock releases the lock of key taken with lock.
+func (m *lockManager) unlock(key string) {
+	m.mu.Lock()
+	l := m.locks[key]
+	l.users--
+	if l.users == 0 {
+		delete(m.locks, key)
+	}
+	m.mu.Unlock()
+
+	l.Unlock()
+}
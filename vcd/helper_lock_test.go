@@ -0,0 +1,115 @@
+package vcd
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockManagerSameKey(t *testing.T) {
+	m := newLockManager()
+
+	var mu sync.Mutex
+	holders, maxHolders := 0, 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.lock("gw")
+			defer m.unlock("gw")
+
+			mu.Lock()
+			holders++
+			if holders > maxHolders {
+				maxHolders = holders
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxHolders != 1 {
+		t.Errorf("Expected one holder of the lock at a time, got %d", maxHolders)
+	}
+	if len(m.locks) != 0 {
+		t.Errorf("Expected the unused lock to be dropped, got %#v", m.locks)
+	}
+}
+
+func TestLockManagerDifferentKeys(t *testing.T) {
+	m := newLockManager()
+
+	m.lock("gw1")
+
+	// gw2 can be locked and unlocked while gw1 is held
+	done := make(chan struct{})
+	go func() {
+		m.lock("gw2")
+		m.unlock("gw2")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the lock of gw2 not to wait for gw1")
+	}
+
+	if _, ok := m.locks["gw2"]; ok {
+		t.Errorf("Expected the lock of gw2 to be dropped once unused")
+	}
+	if l, ok := m.locks["gw1"]; !ok || l.users != 1 {
+		t.Errorf("Expected the lock of gw1 to have one user, got %#v", m.locks["gw1"])
+	}
+
+	m.unlock("gw1")
+	if len(m.locks) != 0 {
+		t.Errorf("Expected the unused lock to be dropped, got %#v", m.locks)
+	}
+}
+
+func TestLockManagerWaitingUsers(t *testing.T) {
+	m := newLockManager()
+
+	m.lock("gw")
+
+	locked := make(chan struct{})
+	go func() {
+		m.lock("gw")
+		close(locked)
+	}()
+
+	// The waiting caller keeps the lock in use
+	for i := 0; i < 1000; i++ {
+		m.mu.Lock()
+		users := m.locks["gw"].users
+		m.mu.Unlock()
+		if users == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	m.unlock("gw")
+	<-locked
+
+	m.mu.Lock()
+	l, ok := m.locks["gw"]
+	m.mu.Unlock()
+	if !ok || l.users != 1 {
+		t.Errorf("Expected the lock to be kept for the waiting caller, got %#v", l)
+	}
+
+	m.unlock("gw")
+	if len(m.locks) != 0 {
+		t.Errorf("Expected the unused lock to be dropped, got %#v", m.locks)
+	}
+}
@@ -0,0 +1,90 @@
+package vcd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/vCloud/govcloudair"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+// executeRequestWithBody works like govcloudair.ExecuteRequest, but sends the
+// payload regardless of the HTTP method. ExecuteRequest only attaches a body
+// to POST requests, which rules out the PUT calls used to replace a section
+// of an entity.
+func executeRequestWithBody(payload, path, method, contentType string, client *govcloudair.Client) (govcloudair.Task, error) {
+	s, err := url.ParseRequestURI(path)
+	if err != nil {
+		return govcloudair.Task{}, fmt.Errorf("Error parsing url %s: %s", path, err)
+	}
+
+	log.Printf("[TRACE] %s %s\n%s", method, path, payload)
+
+	req := client.NewRequest(map[string]string{}, method, *s, bytes.NewBufferString(xml.Header+payload))
+	req.Header.Add("Content-Type", contentType)
+
+	task := govcloudair.NewTask(client)
+	err = doRequest(client, req, task.Task)
+	if err != nil {
+		return govcloudair.Task{}, err
+	}
+
+	return *task, nil
+}
+
+// getEntity fetches the entity found at path and decodes it into out.
+func getEntity(path string, out interface{}, client *govcloudair.Client) error {
+	s, err := url.ParseRequestURI(path)
+	if err != nil {
+		return fmt.Errorf("Error parsing url %s: %s", path, err)
+	}
+
+	req := client.NewRequest(map[string]string{}, "GET", *s, nil)
+
+	return doRequest(client, req, out)
+}
+
+// doRequest executes req and decodes the XML response into out. Error
+// responses are returned as *types.Error, so the retry helpers can tell a
+// busy entity from a real failure.
+func doRequest(client *govcloudair.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		vcdError := new(types.Error)
+		if err := xml.Unmarshal(body, vcdError); err != nil {
+			return fmt.Errorf("Unexpected API response %s: %s", resp.Status, body)
+		}
+		return vcdError
+	}
+
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+
+	return xml.Unmarshal(body, out)
+}
+
+// queryRecords runs a query through the query service with the given
+// parameters and decodes the result into out.
+func queryRecords(params map[string]string, out interface{}, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	req := vcdClient.Client.NewRequest(params, "GET", vcdClient.QueryHREF, nil)
+
+	return doRequest(&vcdClient.Client, req, out)
+}
@@ -38,55 +38,132 @@ func expandIPRange(configured []interface{}) types.IPRanges {
 	return ipRanges
 }
 
-func expandFirewallRules(d *schema.ResourceData, gateway *types.EdgeGateway) ([]*types.FirewallRule, error) {
-	//firewallRules := make([]*types.FirewallRule, 0, len(configured))
-	firewallRules := gateway.Configuration.EdgeGatewayServiceConfiguration.FirewallService.FirewallRule
-
-	rulesCount := d.Get("rule.#").(int)
-	for i := 0; i < rulesCount; i++ {
-		prefix := fmt.Sprintf("rule.%d", i)
-
-		var protocol *types.FirewallRuleProtocols
-		switch d.Get(prefix + ".protocol").(string) {
-		case "tcp":
-			protocol = &types.FirewallRuleProtocols{
-				TCP: true,
-			}
-		case "udp":
-			protocol = &types.FirewallRuleProtocols{
-				UDP: true,
-			}
-		case "icmp":
-			protocol = &types.FirewallRuleProtocols{
-				ICMP: true,
-			}
-		default:
-			protocol = &types.FirewallRuleProtocols{
-				Any: true,
-			}
-		}
-		rule := &types.FirewallRule{
-			//ID: strconv.Itoa(len(configured) - i),
-			IsEnabled:            true,
-			MatchOnTranslate:     false,
-			Description:          d.Get(prefix + ".description").(string),
-			Policy:               d.Get(prefix + ".policy").(string),
-			Protocols:            protocol,
-			Port:                 getNumericPort(d.Get(prefix + ".destination_port")),
-			DestinationPortRange: d.Get(prefix + ".destination_port").(string),
-			DestinationIP:        d.Get(prefix + ".destination_ip").(string),
-			SourcePort:           getNumericPort(d.Get(prefix + ".source_port")),
-			SourcePortRange:      d.Get(prefix + ".source_port").(string),
-			SourceIP:             d.Get(prefix + ".source_ip").(string),
-			EnableLogging:        false,
+// expandFirewallRule builds the firewall rule configured by the attributes
+// starting with prefix.
+func expandFirewallRule(d *schema.ResourceData, prefix string) *FirewallRule {
+	protocols := expandFirewallRuleProtocol(d.Get(prefix + "protocol").(string))
+	if strings.ToLower(d.Get(prefix+"protocol").(string)) == "other" {
+		protocols = &FirewallRuleProtocols{
+			Other: d.Get(prefix + "other_protocol").(string),
 		}
-		firewallRules = append(firewallRules, rule)
 	}
 
-	return firewallRules, nil
+	return &FirewallRule{
+		IsEnabled:            d.Get(prefix + "enabled").(bool),
+		MatchOnTranslate:     d.Get(prefix + "match_on_translate").(bool),
+		Description:          d.Get(prefix + "description").(string),
+		Policy:               d.Get(prefix + "policy").(string),
+		Protocols:            protocols,
+		IcmpSubType:          d.Get(prefix + "icmp_sub_type").(string),
+		Port:                 getNumericPort(d.Get(prefix + "destination_port")),
+		DestinationPortRange: d.Get(prefix + "destination_port").(string),
+		DestinationIP:        d.Get(prefix + "destination_ip").(string),
+		DestinationVM:        expandVMSelection(d.Get(prefix + "destination_vm").([]interface{})),
+		SourcePort:           getNumericPort(d.Get(prefix + "source_port")),
+		SourcePortRange:      d.Get(prefix + "source_port").(string),
+		SourceIP:             d.Get(prefix + "source_ip").(string),
+		SourceVM:             expandVMSelection(d.Get(prefix + "source_vm").([]interface{})),
+		Direction:            d.Get(prefix + "direction").(string),
+		EnableLogging:        d.Get(prefix + "logging").(bool),
+	}
+}
+
+func expandVMSelection(configured []interface{}) *types.VMSelection {
+	if len(configured) == 0 || configured[0] == nil {
+		return nil
+	}
+
+	data := configured[0].(map[string]interface{})
+	return &types.VMSelection{
+		VAppScopedVMID: data["vapp_scoped_vm_id"].(string),
+		VMNicID:        data["vm_nic_id"].(int),
+		IPType:         data["ip_type"].(string),
+	}
 }
 
-func getProtocol(protocol types.FirewallRuleProtocols) string {
+// flattenFirewallRule is the reverse of expandFirewallRule.
+func flattenFirewallRule(rule *FirewallRule) map[string]interface{} {
+	protocol := "any"
+	otherProtocol := ""
+	if rule.Protocols != nil {
+		protocol = getProtocol(*rule.Protocols)
+		otherProtocol = rule.Protocols.Other
+	}
+
+	destinationPort := rule.DestinationPortRange
+	if destinationPort == "" {
+		destinationPort = getPortString(rule.Port)
+	}
+
+	sourcePort := rule.SourcePortRange
+	if sourcePort == "" {
+		sourcePort = getPortString(rule.SourcePort)
+	}
+
+	return map[string]interface{}{
+		"id":                 rule.ID,
+		"description":        rule.Description,
+		"enabled":            rule.IsEnabled,
+		"policy":             rule.Policy,
+		"protocol":           protocol,
+		"other_protocol":     otherProtocol,
+		"icmp_sub_type":      rule.IcmpSubType,
+		"destination_port":   destinationPort,
+		"destination_ip":     rule.DestinationIP,
+		"destination_vm":     flattenVMSelection(rule.DestinationVM),
+		"source_port":        sourcePort,
+		"source_ip":          rule.SourceIP,
+		"source_vm":          flattenVMSelection(rule.SourceVM),
+		"direction":          rule.Direction,
+		"match_on_translate": rule.MatchOnTranslate,
+		"logging":            rule.EnableLogging,
+	}
+}
+
+func flattenVMSelection(selection *types.VMSelection) []interface{} {
+	if selection == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"vapp_scoped_vm_id": selection.VAppScopedVMID,
+			"vm_nic_id":         selection.VMNicID,
+			"ip_type":           selection.IPType,
+		},
+	}
+}
+
+func expandFirewallRuleProtocol(protocol string) *FirewallRuleProtocols {
+	switch strings.ToLower(protocol) {
+	case "tcp":
+		return &FirewallRuleProtocols{
+			TCP: true,
+		}
+	case "udp":
+		return &FirewallRuleProtocols{
+			UDP: true,
+		}
+	case "tcp+udp":
+		return &FirewallRuleProtocols{
+			TCP: true,
+			UDP: true,
+		}
+	case "icmp":
+		return &FirewallRuleProtocols{
+			ICMP: true,
+		}
+	default:
+		return &FirewallRuleProtocols{
+			Any: true,
+		}
+	}
+}
+
+func getProtocol(protocol FirewallRuleProtocols) string {
+	if protocol.TCP && protocol.UDP {
+		return "tcp+udp"
+	}
 	if protocol.TCP {
 		return "tcp"
 	}
@@ -96,6 +173,9 @@ func getProtocol(protocol types.FirewallRuleProtocols) string {
 	if protocol.ICMP {
 		return "icmp"
 	}
+	if protocol.Other != "" {
+		return "other"
+	}
 	return "any"
 }
 
@@ -115,6 +195,21 @@ func getPortString(port int) string {
 	return portstring
 }
 
+// atoiOrZero converts the numeric strings used by the vCD API, treating
+// missing values as 0.
+func atoiOrZero(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
+// itoaOrEmpty is the reverse of atoiOrZero, leaving out 0 values.
+func itoaOrEmpty(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
 func retryCall(seconds int, f resource.RetryFunc) error {
 	return resource.Retry(time.Duration(seconds)*time.Second, f)
 }
@@ -167,6 +262,71 @@ func retryCallWithBusyEntityErrorHandling(seconds int, f func() (govcloudair.Tas
 	})
 }
 
+// isNotFoundError reports whether err is the error vCloud Director returns
+// for an entity that doesn't exist (anymore). Deleted entities are reported
+// as forbidden rather than missing.
+func isNotFoundError(err error) bool {
+	vcdError, ok := err.(*types.Error)
+	if !ok {
+		return false
+	}
+	return vcdError.MajorErrorCode == 404 ||
+		(vcdError.MajorErrorCode == 403 && vcdError.MinorErrorCode == "ACCESS_TO_RESOURCE_IS_FORBIDDEN")
+}
+
+// edgeGatewayNatRules returns the NAT rules of the edge gateway, if any.
+func edgeGatewayNatRules(gateway *EdgeGateway) []*types.NatRule {
+	if gateway.Configuration == nil ||
+		gateway.Configuration.EdgeGatewayServiceConfiguration == nil ||
+		gateway.Configuration.EdgeGatewayServiceConfiguration.NatService == nil {
+		return nil
+	}
+	return gateway.Configuration.EdgeGatewayServiceConfiguration.NatService.NatRule
+}
+
+// natRulesOf returns the rules of the NAT service, if any.
+func natRulesOf(service *types.NatService) []*types.NatRule {
+	if service == nil {
+		return nil
+	}
+	return service.NatRule
+}
+
+// findNatRuleID returns the Id of the first rule in rules that matches, or
+// an empty string when none does. It is only used to find the rules of state
+// kept before rules had Ids.
+func findNatRuleID(rules []*types.NatRule, match func(*types.NatRule) bool) string {
+	for _, rule := range rules {
+		if match(rule) {
+			return rule.ID
+		}
+	}
+
+	return ""
+}
+
+// findNewNatRuleID returns the Id vCD gave to rule. Like for firewall rules,
+// see findFirewallRuleID, vCD keeps the order of the sent rules, so the Id is
+// the one of the rule at the position of rule in sent.
+func findNewNatRuleID(rules, sent []*types.NatRule, rule *types.NatRule) (string, error) {
+	if len(rules) != len(sent) {
+		return "", fmt.Errorf("Sent %d NAT rules, found %d", len(sent), len(rules))
+	}
+
+	for i, candidate := range sent {
+		if candidate != rule {
+			continue
+		}
+
+		if rules[i].ID == "" || findNatRule(sent, rules[i].ID) != nil {
+			return "", fmt.Errorf("NAT rule %d has no new Id", i+1)
+		}
+		return rules[i].ID, nil
+	}
+
+	return "", fmt.Errorf("Unable to find rule")
+}
+
 func convertToStringMap(param map[string]interface{}) map[string]string {
 	temp := make(map[string]string)
 	for k, v := range param {
@@ -258,6 +418,11 @@ func deepCopyMap(m map[string]interface{}) (map[string]interface{}, error) {
 	return copy, nil
 }
 
+// Suppress Diff on values vCloud returns in a different case, like "Any"
+func suppressCaseDifferences(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
 func IsIPv4(str string) bool {
 	ip := net.ParseIP(str)
 	return ip != nil && strings.Contains(str, ".")
@@ -1,13 +1,20 @@
 package vcd
 
 import (
+	"encoding/xml"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vCloud/govcloudair"
 	types "github.com/vCloud/govcloudair/types/v56"
 )
 
+// vAppStatusPoweredOn is the status code of a powered on vApp, see
+// types.VAppStatuses.
+const vAppStatusPoweredOn = 4
+
 func readVApp(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
 
@@ -24,6 +31,10 @@ func readVApp(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	// Reading networks defined on the vApp
+	section, err := getVAppNetworkConfigSection(d.Id(), meta)
+	if err != nil {
+		return err
+	}
 
 	organizationNetworksFromState := interfaceListToStringList(
 		d.Get("organization_network").([]interface{}))
@@ -37,61 +48,28 @@ func readVApp(d *schema.ResourceData, meta interface{}) error {
 	// // Order is not guarenteed, so we will have to check what we have first
 	// // First look for networks that we already have
 	for _, network := range organizationNetworksFromState {
-		vAppNetwork, _ := vapp.GetNetworkByName(network)
-		if err != nil {
-			return err
-		}
-
-		if vAppNetwork != nil {
+		if findVAppNetworkConfiguration(section, network) != nil {
 			readOrgNetworks = append(readOrgNetworks, network)
 		}
 	}
 
+	var vms []*types.VM
+	if vapp.VApp.Children != nil {
+		vms = vapp.VApp.Children.VM
+	}
+
 	for index := range vAppNetworksFromState {
 		vAppNetworkResource := NewVAppNetworkSubresource(vAppNetworksFromState[index], nil)
-		vAppNetwork, _ := vapp.GetNetworkByName(vAppNetworkResource.Get("name").(string))
-		if err != nil {
-			return err
-		}
-
+		vAppNetwork := findVAppNetworkConfiguration(section, vAppNetworkResource.Get("name").(string))
 		if vAppNetwork != nil {
-			vAppNetworkResource.Set("name",
-				vAppNetwork.NetworkName)
-			// vAppNetworkResource.Set("description", vAppnetwork.Configuration)
-			vAppNetworkResource.Set("gateway",
-				vAppNetwork.Configuration.IPScopes.IPScope.Gateway)
-			vAppNetworkResource.Set("netmask",
-				vAppNetwork.Configuration.IPScopes.IPScope.Netmask)
-			vAppNetworkResource.Set("dns1",
-				vAppNetwork.Configuration.IPScopes.IPScope.DNS1)
-			vAppNetworkResource.Set("dns2",
-				vAppNetwork.Configuration.IPScopes.IPScope.DNS2)
-			vAppNetworkResource.Set("start",
-				vAppNetwork.Configuration.IPScopes.IPScope.IPRanges.IPRange[0].StartAddress)
-			vAppNetworkResource.Set("end",
-				vAppNetwork.Configuration.IPScopes.IPScope.IPRanges.IPRange[0].EndAddress)
-
-			if vAppNetwork.Configuration.ParentNetwork != nil {
-				vAppNetworkResource.Set("parent",
-					vAppNetwork.Configuration.ParentNetwork.Name)
-			}
-
-			if vAppNetwork.Configuration.Features != nil {
-				if vAppNetwork.Configuration.Features.NatService != nil {
-					vAppNetworkResource.Set("nat",
-						vAppNetwork.Configuration.Features.NatService.IsEnabled)
-				}
-
-				if vAppNetwork.Configuration.Features.DhcpService != nil {
-					vAppNetworkResource.Set("dhcp",
-						vAppNetwork.Configuration.Features.DhcpService.IsEnabled)
-				}
-			}
+			flattenVAppNetworkConfiguration(vAppNetworkResource, vAppNetwork, vms)
 
 			readVAppNetworks = append(readVAppNetworks, vAppNetworkResource.Data())
 		}
 	}
 
+	d.Set("power_on", vapp.VApp.Status == vAppStatusPoweredOn)
+
 	log.Printf("[TRACE] Org Networks defined for vApp (%s) is: %#v", vapp.VApp.Name, readOrgNetworks)
 	log.Printf("[TRACE] vApp Networks defined for vApp (%s) is: %#v", vapp.VApp.Name, readOrgNetworks)
 
@@ -102,14 +80,14 @@ func readVApp(d *schema.ResourceData, meta interface{}) error {
 
 }
 
-func createNetworkConfiguration(d *schema.ResourceData, meta interface{}) ([]*types.VAppNetworkConfiguration, error) {
+func createNetworkConfiguration(d *schema.ResourceData, meta interface{}) ([]*VAppNetworkConfiguration, error) {
 	vcdClient := meta.(*VCDClient)
 
 	// Organization Network
 	organizationNetworks := d.Get("organization_network").([]interface{})
 	log.Printf("[TRACE] Networks from state: %#v", organizationNetworks)
 
-	orgnetworks := make([]*types.VAppNetworkConfiguration, len(organizationNetworks))
+	orgnetworks := make([]*VAppNetworkConfiguration, len(organizationNetworks))
 	for index, network := range organizationNetworks {
 		orgnetwork, err := vcdClient.OrgVdc.FindVDCNetwork(network.(string))
 		if err != nil {
@@ -122,81 +100,346 @@ func createNetworkConfiguration(d *schema.ResourceData, meta interface{}) ([]*ty
 	vAppNetworksInterfaceList := d.Get("vapp_network").([]interface{})
 	vAppNetworks := interfaceListToMapStringInterface(vAppNetworksInterfaceList)
 
-	vAppNetworkConfigurations := make([]*types.VAppNetworkConfiguration, len(vAppNetworks))
+	// NAT rules refer to VMs by their id within the vApp, which only exist
+	// once the vApp has been created
+	var vms []*types.VM
+	if d.Id() != "" {
+		vapp, err := vcdClient.OrgVdc.GetVAppByHREF(d.Id())
+		if err != nil {
+			return nil, fmt.Errorf("Error finding VApp: %#v", err)
+		}
+		if vapp.VApp.Children != nil {
+			vms = vapp.VApp.Children.VM
+		}
+	}
+
+	vAppNetworkConfigurations := make([]*VAppNetworkConfiguration, len(vAppNetworks))
 	for index := range vAppNetworks {
 		vAppNetwork := NewVAppNetworkSubresource(vAppNetworks[index], nil)
 
-		configuration := &types.NetworkConfiguration{
-			FenceMode: types.FenceModeIsolated,
-			Features:  &types.NetworkFeatures{},
-			IPScopes: &types.IPScopes{
-				IPScope: types.IPScope{
-					IsInherited: false,
-					Gateway:     vAppNetwork.Get("gateway").(string),
-					Netmask:     vAppNetwork.Get("netmask").(string),
-					DNS1:        vAppNetwork.Get("dns1").(string),
-					DNS2:        vAppNetwork.Get("dns2").(string),
-					IsEnabled:   true,
-					IPRanges: &types.IPRanges{
-						IPRange: []*types.IPRange{&types.IPRange{
-							StartAddress: vAppNetwork.Get("start").(string),
-							EndAddress:   vAppNetwork.Get("end").(string),
-						}},
-					},
-				},
+		configuration, err := expandVAppNetworkConfiguration(vAppNetwork, vms, meta)
+		if err != nil {
+			return nil, err
+		}
+
+		vAppNetworkConfigurations[index] = configuration
+	}
+
+	networks := append(orgnetworks, vAppNetworkConfigurations...)
+	return networks, nil
+}
+
+// expandVAppNetworkConfiguration builds the configuration of a single vApp
+// network. vms are the VMs of the vApp, which NAT rules refer to.
+func expandVAppNetworkConfiguration(vAppNetwork *VAppNetworkSubresource, vms []*types.VM, meta interface{}) (*VAppNetworkConfiguration, error) {
+	vcdClient := meta.(*VCDClient)
+
+	ipRanges := expandIPRange(vAppNetwork.Get("static_ip_pool").([]interface{}))
+
+	configuration := &NetworkConfiguration{
+		FenceMode: types.FenceModeIsolated,
+		Features:  &NetworkFeatures{},
+		IPScopes: &types.IPScopes{
+			IPScope: types.IPScope{
+				IsInherited: false,
+				Gateway:     vAppNetwork.Get("gateway").(string),
+				Netmask:     vAppNetwork.Get("netmask").(string),
+				DNS1:        vAppNetwork.Get("dns1").(string),
+				DNS2:        vAppNetwork.Get("dns2").(string),
+				IsEnabled:   true,
+				IPRanges:    &ipRanges,
+			},
+		},
+		GuestVlanAllowed:               vAppNetwork.Get("guest_vlan_allowed").(bool),
+		RetainNetInfoAcrossDeployments: vAppNetwork.Get("retain_ip_mac_resources").(bool),
+	}
+
+	for _, dhcp := range interfaceListToMapStringInterface(vAppNetwork.Get("dhcp").([]interface{})) {
+		configuration.Features.DhcpService = &types.DhcpService{
+			IsEnabled: true,
+			IPRange: &types.IPRange{
+				StartAddress: dhcp["start_address"].(string),
+				EndAddress:   dhcp["end_address"].(string),
 			},
+			DefaultLeaseTime:    dhcp["default_lease_time"].(int),
+			MaxLeaseTime:        dhcp["max_lease_time"].(int),
+			PrimaryNameServer:   configuration.IPScopes.IPScope.DNS1,
+			SecondaryNameServer: configuration.IPScopes.IPScope.DNS2,
+			SubMask:             configuration.IPScopes.IPScope.Netmask,
+			RouterIP:            configuration.IPScopes.IPScope.Gateway,
+		}
+	}
+
+	if vAppNetwork.Get("nat").(bool) {
+		configuration.Features.NatService = &types.NatService{
+			IsEnabled: true,
+			NatType:   vAppNetwork.Get("nat_type").(string),
+			Policy:    vAppNetwork.Get("nat_policy").(string),
+			// We need to set parent
 		}
 
-		if vAppNetwork.Get("dhcp").(bool) {
-			configuration.Features.DhcpService = &types.DhcpService{
-				IsEnabled: true,
-				IPRange: &types.IPRange{
-					StartAddress: vAppNetwork.Get("dhcp_start").(string),
-					EndAddress:   vAppNetwork.Get("dhcp_end").(string),
-				},
-				PrimaryNameServer:   configuration.IPScopes.IPScope.DNS1,
-				SecondaryNameServer: configuration.IPScopes.IPScope.DNS1,
-				SubMask:             configuration.IPScopes.IPScope.Netmask,
-				RouterIP:            configuration.IPScopes.IPScope.Gateway,
+		err := expandVAppNetworkFeatures(vAppNetwork, configuration.Features, vms)
+		if err != nil {
+			return nil, err
+		}
+
+		orgnetwork, err := vcdClient.OrgVdc.FindVDCNetwork(vAppNetwork.Get("parent").(string))
+
+		if err != nil {
+			return nil, fmt.Errorf("Error finding vdc org network: %s, %#v", vAppNetwork.Get("parent").(string), err)
+		}
+		configuration.ParentNetwork = &types.Reference{
+			HREF: orgnetwork.OrgVDCNetwork.HREF,
+			ID:   orgnetwork.OrgVDCNetwork.ID,
+			Name: orgnetwork.OrgVDCNetwork.Name,
+		}
+
+		configuration.FenceMode = types.FenceModeNAT
+	} else if len(vAppNetwork.Get("firewall_rule").([]interface{})) > 0 ||
+		len(vAppNetwork.Get("nat_rule").([]interface{})) > 0 ||
+		len(vAppNetwork.Get("static_route").([]interface{})) > 0 {
+		return nil, fmt.Errorf("vApp network %s: firewall_rule, nat_rule and static_route require nat to be enabled", vAppNetwork.Get("name").(string))
+	}
+
+	return &VAppNetworkConfiguration{
+		Configuration: configuration,
+		NetworkName:   vAppNetwork.Get("name").(string),
+		Description:   vAppNetwork.Get("description").(string),
+	}, nil
+}
+
+// flattenVAppNetworkConfiguration reads the configuration of a single vApp
+// network into vAppNetworkResource.
+func flattenVAppNetworkConfiguration(vAppNetworkResource *VAppNetworkSubresource, vAppNetwork *VAppNetworkConfiguration, vms []*types.VM) {
+	vAppNetworkResource.Set("name", vAppNetwork.NetworkName)
+	vAppNetworkResource.Set("description", vAppNetwork.Description)
+
+	configuration := vAppNetwork.Configuration
+	if configuration == nil {
+		return
+	}
+
+	staticIPPools := make([]map[string]interface{}, 0)
+	if configuration.IPScopes != nil {
+		vAppNetworkResource.Set("gateway", configuration.IPScopes.IPScope.Gateway)
+		vAppNetworkResource.Set("netmask", configuration.IPScopes.IPScope.Netmask)
+		vAppNetworkResource.Set("dns1", configuration.IPScopes.IPScope.DNS1)
+		vAppNetworkResource.Set("dns2", configuration.IPScopes.IPScope.DNS2)
+
+		if configuration.IPScopes.IPScope.IPRanges != nil {
+			for _, ipRange := range configuration.IPScopes.IPScope.IPRanges.IPRange {
+				staticIPPools = append(staticIPPools, map[string]interface{}{
+					"start_address": ipRange.StartAddress,
+					"end_address":   ipRange.EndAddress,
+				})
+			}
+		}
+	}
+	vAppNetworkResource.Set("static_ip_pool", staticIPPools)
+
+	vAppNetworkResource.Set("guest_vlan_allowed", configuration.GuestVlanAllowed)
+	vAppNetworkResource.Set("retain_ip_mac_resources", configuration.RetainNetInfoAcrossDeployments)
+
+	if configuration.ParentNetwork != nil {
+		vAppNetworkResource.Set("parent", configuration.ParentNetwork.Name)
+	}
+
+	vAppNetworkResource.Set("nat", configuration.FenceMode == types.FenceModeNAT)
+
+	dhcp := make([]map[string]interface{}, 0)
+	if configuration.Features != nil {
+		if configuration.Features.NatService != nil {
+			vAppNetworkResource.Set("nat", configuration.Features.NatService.IsEnabled)
+		}
+
+		flattenVAppNetworkFeatures(vAppNetworkResource, configuration.Features, vms)
+
+		if dhcpService := configuration.Features.DhcpService; dhcpService != nil && dhcpService.IsEnabled && dhcpService.IPRange != nil {
+			dhcp = append(dhcp, map[string]interface{}{
+				"start_address":      dhcpService.IPRange.StartAddress,
+				"end_address":        dhcpService.IPRange.EndAddress,
+				"default_lease_time": dhcpService.DefaultLeaseTime,
+				"max_lease_time":     dhcpService.MaxLeaseTime,
+			})
+		}
+	}
+	vAppNetworkResource.Set("dhcp", dhcp)
+}
+
+// expandVAppNetworkFeatures adds the firewall, NAT rules and static routes of
+// a NAT routed vApp network to its features.
+func expandVAppNetworkFeatures(vAppNetwork *VAppNetworkSubresource, features *NetworkFeatures, vms []*types.VM) error {
+	firewallRules := interfaceListToMapStringInterface(vAppNetwork.Get("firewall_rule").([]interface{}))
+	if len(firewallRules) > 0 {
+		features.FirewallService = &FirewallService{
+			IsEnabled:     true,
+			DefaultAction: vAppNetwork.Get("firewall_default_action").(string),
+		}
+
+		for _, rule := range firewallRules {
+			features.FirewallService.FirewallRule = append(features.FirewallService.FirewallRule, &FirewallRule{
+				IsEnabled:            rule["enabled"].(bool),
+				Description:          rule["description"].(string),
+				Policy:               rule["policy"].(string),
+				Protocols:            expandFirewallRuleProtocol(rule["protocol"].(string)),
+				Port:                 getNumericPort(rule["destination_port"]),
+				DestinationPortRange: rule["destination_port"].(string),
+				DestinationIP:        rule["destination_ip"].(string),
+				SourcePort:           getNumericPort(rule["source_port"]),
+				SourcePortRange:      rule["source_port"].(string),
+				SourceIP:             rule["source_ip"].(string),
+				EnableLogging:        rule["logging"].(bool),
+			})
+		}
+	}
+
+	natType := vAppNetwork.Get("nat_type").(string)
+	for _, rule := range interfaceListToMapStringInterface(vAppNetwork.Get("nat_rule").([]interface{})) {
+		// The VMs of a vApp are created after the vApp itself, rules for VMs
+		// that don't exist yet show up as a change and are added on the next
+		// apply
+		vmID := vAppScopedVMID(vms, rule["vm_name"].(string))
+		if vmID == "" {
+			log.Printf("[WARN] vApp network %s: VM %s doesn't exist yet, skipping its NAT rule",
+				vAppNetwork.Get("name").(string), rule["vm_name"].(string))
+			continue
+		}
+
+		natRule := &types.NatRule{}
+		if natType == "portForwarding" {
+			natRule.VMRule = &types.NatVMRule{
+				ExternalIPAddress: rule["external_ip"].(string),
+				ExternalPort:      rule["external_port"].(int),
+				VAppScopedVMID:    vmID,
+				VMNicID:           rule["vm_nic_id"].(int),
+				InternalPort:      rule["internal_port"].(int),
+				Protocol:          rule["protocol"].(string),
 			}
+		} else {
+			natRule.OneToOneVMRule = &types.NatOneToOneVMRule{
+				MappingMode:       rule["mapping_mode"].(string),
+				ExternalIPAddress: rule["external_ip"].(string),
+				VAppScopedVMID:    vmID,
+				VMNicID:           rule["vm_nic_id"].(int),
+			}
+		}
+		features.NatService.NatRule = append(features.NatService.NatRule, natRule)
+	}
+
+	staticRoutes := interfaceListToMapStringInterface(vAppNetwork.Get("static_route").([]interface{}))
+	if len(staticRoutes) > 0 {
+		features.StaticRoutingService = &StaticRoutingService{
+			IsEnabled: true,
 		}
 
-		if vAppNetwork.Get("nat").(bool) {
-			configuration.Features.NatService = &types.NatService{
-				IsEnabled: true,
-				NatType:   "ipTranslation",
-				Policy:    "allowTrafficIn",
-				// We need to set parent
+		for _, route := range staticRoutes {
+			features.StaticRoutingService.StaticRoute = append(features.StaticRoutingService.StaticRoute, &types.StaticRoute{
+				Name:      route["name"].(string),
+				Network:   route["network"].(string),
+				NextHopIP: route["next_hop_ip"].(string),
+				Interface: route["interface"].(string),
+			})
+		}
+	}
+
+	return nil
+}
+
+// flattenVAppNetworkFeatures reads the NAT settings, firewall, NAT rules and
+// static routes of a vApp network. vCloud adds default firewall and NAT rules
+// to routed networks, so rules are only read back when they are managed.
+func flattenVAppNetworkFeatures(vAppNetwork *VAppNetworkSubresource, features *NetworkFeatures, vms []*types.VM) {
+	if features.NatService != nil {
+		vAppNetwork.Set("nat_type", features.NatService.NatType)
+		vAppNetwork.Set("nat_policy", features.NatService.Policy)
+
+		if len(vAppNetwork.Get("nat_rule").([]interface{})) > 0 {
+			natRules := make([]map[string]interface{}, 0, len(features.NatService.NatRule))
+			for _, rule := range features.NatService.NatRule {
+				natRule := make(map[string]interface{})
+				switch {
+				case rule.VMRule != nil:
+					natRule["vm_name"] = vAppScopedVMName(vms, rule.VMRule.VAppScopedVMID)
+					natRule["vm_nic_id"] = rule.VMRule.VMNicID
+					natRule["external_ip"] = rule.VMRule.ExternalIPAddress
+					natRule["external_port"] = rule.VMRule.ExternalPort
+					natRule["internal_port"] = rule.VMRule.InternalPort
+					natRule["protocol"] = rule.VMRule.Protocol
+				case rule.OneToOneVMRule != nil:
+					natRule["vm_name"] = vAppScopedVMName(vms, rule.OneToOneVMRule.VAppScopedVMID)
+					natRule["vm_nic_id"] = rule.OneToOneVMRule.VMNicID
+					natRule["mapping_mode"] = rule.OneToOneVMRule.MappingMode
+					natRule["external_ip"] = rule.OneToOneVMRule.ExternalIPAddress
+				default:
+					continue
+				}
+				natRules = append(natRules, natRule)
 			}
+			vAppNetwork.Set("nat_rule", natRules)
+		}
+	}
 
-			orgnetwork, err := vcdClient.OrgVdc.FindVDCNetwork(vAppNetwork.Get("parent").(string))
+	if features.FirewallService != nil && len(vAppNetwork.Get("firewall_rule").([]interface{})) > 0 {
+		vAppNetwork.Set("firewall_default_action", features.FirewallService.DefaultAction)
 
-			if err != nil {
-				return nil, fmt.Errorf("Error finding vdc org network: %s, %#v", vAppNetwork.Get("parent").(string), err)
+		firewallRules := make([]map[string]interface{}, len(features.FirewallService.FirewallRule))
+		for index, rule := range features.FirewallService.FirewallRule {
+			protocol := "any"
+			if rule.Protocols != nil {
+				protocol = getProtocol(*rule.Protocols)
 			}
-			configuration.ParentNetwork = &types.Reference{
-				HREF: orgnetwork.OrgVDCNetwork.HREF,
-				ID:   orgnetwork.OrgVDCNetwork.ID,
-				Name: orgnetwork.OrgVDCNetwork.Name,
+
+			firewallRules[index] = map[string]interface{}{
+				"description":      rule.Description,
+				"enabled":          rule.IsEnabled,
+				"policy":           rule.Policy,
+				"protocol":         protocol,
+				"destination_port": rule.DestinationPortRange,
+				"destination_ip":   rule.DestinationIP,
+				"source_port":      rule.SourcePortRange,
+				"source_ip":        rule.SourceIP,
+				"logging":          rule.EnableLogging,
 			}
+		}
+		vAppNetwork.Set("firewall_rule", firewallRules)
+	}
 
-			configuration.FenceMode = types.FenceModeNAT
+	staticRoutes := make([]map[string]interface{}, 0)
+	if features.StaticRoutingService != nil {
+		for _, route := range features.StaticRoutingService.StaticRoute {
+			staticRoutes = append(staticRoutes, map[string]interface{}{
+				"name":        route.Name,
+				"network":     route.Network,
+				"next_hop_ip": route.NextHopIP,
+				"interface":   route.Interface,
+			})
 		}
+	}
+	vAppNetwork.Set("static_route", staticRoutes)
+}
 
-		vAppNetworkConfigurations[index] = &types.VAppNetworkConfiguration{
-			Configuration: configuration,
-			NetworkName:   vAppNetwork.Get("name").(string),
+func vAppScopedVMID(vms []*types.VM, name string) string {
+	for _, vm := range vms {
+		if vm.Name == name {
+			return vm.VAppScopedLocalID
 		}
 	}
+	return ""
+}
 
-	networks := append(orgnetworks, vAppNetworkConfigurations...)
-	return networks, nil
+func vAppScopedVMName(vms []*types.VM, id string) string {
+	for _, vm := range vms {
+		if vm.VAppScopedLocalID == id {
+			return vm.Name
+		}
+	}
+	return id
 }
 
-func orgVDCNetworkToNetworkConfiguration(orgnetwork *types.OrgVDCNetwork) *types.VAppNetworkConfiguration {
-	return &types.VAppNetworkConfiguration{
+func orgVDCNetworkToNetworkConfiguration(orgnetwork *types.OrgVDCNetwork) *VAppNetworkConfiguration {
+	return &VAppNetworkConfiguration{
 		NetworkName: orgnetwork.Name,
-		Configuration: &types.NetworkConfiguration{
+		Configuration: &NetworkConfiguration{
 			FenceMode: types.FenceModeBridged,
 			ParentNetwork: &types.Reference{
 				HREF: orgnetwork.HREF,
@@ -207,7 +450,7 @@ func orgVDCNetworkToNetworkConfiguration(orgnetwork *types.OrgVDCNetwork) *types
 	}
 }
 
-func networkInList(networks []string, vAppNetwork *types.VAppNetworkConfiguration) bool {
+func networkInList(networks []string, vAppNetwork *VAppNetworkConfiguration) bool {
 	for _, network := range networks {
 		if network == vAppNetwork.NetworkName {
 			return true
@@ -215,3 +458,275 @@ func networkInList(networks []string, vAppNetwork *types.VAppNetworkConfiguratio
 	}
 	return false
 }
+
+func getVAppStartupSection(vappHREF string, meta interface{}) (*StartupSection, error) {
+	vcdClient := meta.(*VCDClient)
+
+	section := &StartupSection{}
+	err := getEntity(vappHREF+"/startupSection/", section, &vcdClient.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading startup section of vApp %s: %#v", vappHREF, err)
+	}
+
+	return section, nil
+}
+
+func setVAppStartupSection(vappHREF string, section *StartupSection, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	request := &StartupSectionRequest{
+		Ovf:   "http://schemas.dmtf.org/ovf/envelope/1",
+		Xmlns: "http://www.vmware.com/vcloud/v1.5",
+		Info:  "VApp startup section",
+		Item:  make([]StartupItemRequest, len(section.Item)),
+	}
+	for index, item := range section.Item {
+		request.Item[index] = StartupItemRequest(*item)
+	}
+
+	output, err := xml.MarshalIndent(request, "  ", "    ")
+	if err != nil {
+		return fmt.Errorf("Error marshaling startup section: %s", err)
+	}
+
+	return retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+		return executeRequestWithBody(string(output),
+			vappHREF+"/startupSection/",
+			"PUT",
+			"application/vnd.vmware.vcloud.startupSection+xml",
+			&vcdClient.Client)
+	})
+}
+
+// undeployVApp undeploys the vApp with the given power action. Unlike
+// VApp.Undeploy, which always powers off, UndeployPowerActionDefault stops
+// each VM with the stop action and in the order of the startup section.
+func undeployVApp(vapp govcloudair.VApp, action types.UndeployPowerAction, meta interface{}) (govcloudair.Task, error) {
+	vcdClient := meta.(*VCDClient)
+
+	params := &types.UndeployVAppParams{
+		Xmlns:               "http://www.vmware.com/vcloud/v1.5",
+		UndeployPowerAction: action,
+	}
+
+	output, err := xml.MarshalIndent(params, "  ", "    ")
+	if err != nil {
+		return govcloudair.Task{}, fmt.Errorf("Error marshaling undeploy parameters: %s", err)
+	}
+
+	return govcloudair.ExecuteRequest(string(output),
+		vapp.VApp.HREF+"/action/undeploy",
+		"POST",
+		"application/vnd.vmware.vcloud.undeployVAppParams+xml",
+		&vcdClient.Client)
+}
+
+// deployVApp deploys the vApp, optionally powering it on. The VMs are powered
+// on in the order of the startup section.
+func deployVApp(vapp govcloudair.VApp, d *schema.ResourceData, meta interface{}) (govcloudair.Task, error) {
+	vcdClient := meta.(*VCDClient)
+
+	params := &types.DeployVAppParams{
+		Xmlns:                  "http://www.vmware.com/vcloud/v1.5",
+		PowerOn:                d.Get("power_on").(bool),
+		DeploymentLeaseSeconds: d.Get("deployment_lease_seconds").(int),
+		ForceCustomization:     d.Get("force_customization").(bool),
+	}
+
+	output, err := xml.MarshalIndent(params, "  ", "    ")
+	if err != nil {
+		return govcloudair.Task{}, fmt.Errorf("Error marshaling deploy parameters: %s", err)
+	}
+
+	return govcloudair.ExecuteRequest(string(output),
+		vapp.VApp.HREF+"/action/deploy",
+		"POST",
+		"application/vnd.vmware.vcloud.deployVAppParams+xml",
+		&vcdClient.Client)
+}
+
+// setVAppDeploymentLease changes the deployment lease of the vApp through its
+// lease settings section, which unlike deploying again also works on a
+// deployed vApp. The storage lease is kept.
+func setVAppDeploymentLease(vappHREF string, seconds int, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	section := &types.LeaseSettingsSection{}
+	err := getEntity(vappHREF+"/leaseSettingsSection/", section, &vcdClient.Client)
+	if err != nil {
+		return fmt.Errorf("Error reading lease settings of vApp %s: %#v", vappHREF, err)
+	}
+
+	request := &LeaseSettingsSectionRequest{
+		Xmlns:                    "http://www.vmware.com/vcloud/v1.5",
+		Ovf:                      "http://schemas.dmtf.org/ovf/envelope/1",
+		Info:                     "Lease settings section",
+		DeploymentLeaseInSeconds: seconds,
+		StorageLeaseInSeconds:    section.StorageLeaseInSeconds,
+	}
+
+	output, err := xml.MarshalIndent(request, "  ", "    ")
+	if err != nil {
+		return fmt.Errorf("Error marshaling lease settings: %s", err)
+	}
+
+	return retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+		return executeRequestWithBody(string(output),
+			vappHREF+"/leaseSettingsSection/",
+			"PUT",
+			"application/vnd.vmware.vcloud.leaseSettingsSection+xml",
+			&vcdClient.Client)
+	})
+}
+
+// updateVAppNetworkConfigurations replaces the network config section of the
+// vApp with the list returned by update, leaving the entries update doesn't
+// touch as they are. Unlike VApp.SetNetworkConfigurations it doesn't
+// recompose the vApp, so VMs on other networks stay connected.
+func updateVAppNetworkConfigurations(vappHREF string, update func(networks []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error), meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	vcdClient.locks.lock(vappHREF)
+	defer vcdClient.locks.unlock(vappHREF)
+
+	return updateVAppNetworkConfigurationsUnlocked(vappHREF, update, meta)
+}
+
+// updateVAppNetworkConfigurationsUnlocked is updateVAppNetworkConfigurations
+// for callers that already hold the lock of the vApp.
+func updateVAppNetworkConfigurationsUnlocked(vappHREF string, update func(networks []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error), meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	vapp, err := vcdClient.OrgVdc.GetVAppByHREF(vappHREF)
+	if err != nil {
+		return fmt.Errorf("Error finding VApp: %#v", err)
+	}
+
+	current, err := getVAppNetworkConfigSection(vappHREF, meta)
+	if err != nil {
+		return err
+	}
+	networks := current.NetworkConfig
+
+	var vms []*types.VM
+	if vapp.VApp.Children != nil {
+		vms = vapp.VApp.Children.VM
+	}
+
+	networks, err = update(networks, vms)
+	if err != nil {
+		return err
+	}
+
+	section := &NetworkConfigSection{
+		Xmlns:         "http://www.vmware.com/vcloud/v1.5",
+		Ovf:           "http://schemas.dmtf.org/ovf/envelope/1",
+		Info:          "Configuration parameters for logical networks",
+		NetworkConfig: networks,
+	}
+
+	output, err := xml.MarshalIndent(section, "  ", "    ")
+	if err != nil {
+		return fmt.Errorf("Error marshaling network config section: %s", err)
+	}
+
+	return retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+		return executeRequestWithBody(string(output),
+			vappHREF+"/networkConfigSection/",
+			"PUT",
+			"application/vnd.vmware.vcloud.networkConfigSection+xml",
+			&vcdClient.Client)
+	})
+}
+
+// getVAppNetworkConfigSection reads the networks of the vApp found at
+// vappHREF, see NetworkConfigSection.
+func getVAppNetworkConfigSection(vappHREF string, meta interface{}) (*NetworkConfigSection, error) {
+	vcdClient := meta.(*VCDClient)
+
+	section := &NetworkConfigSection{}
+	err := getEntity(vappHREF+"/networkConfigSection/", section, &vcdClient.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading vApp networks: %#v", err)
+	}
+
+	return section, nil
+}
+
+// findVAppNetworkConfiguration returns the vApp network named name, or nil.
+func findVAppNetworkConfiguration(section *NetworkConfigSection, name string) *VAppNetworkConfiguration {
+	for _, network := range section.NetworkConfig {
+		if network.NetworkName == name {
+			return network
+		}
+	}
+	return nil
+}
+
+// replaceVAppNetworkConfiguration returns networks with the entry named name
+// replaced by network, or removed when network is nil. A network that isn't
+// in the list yet is appended.
+func replaceVAppNetworkConfiguration(networks []*VAppNetworkConfiguration, name string, network *VAppNetworkConfiguration) []*VAppNetworkConfiguration {
+	replaced := make([]*VAppNetworkConfiguration, 0, len(networks)+1)
+	found := false
+	for _, existing := range networks {
+		if existing.NetworkName != name {
+			replaced = append(replaced, existing)
+			continue
+		}
+		found = true
+		if network != nil {
+			replaced = append(replaced, network)
+		}
+	}
+
+	if !found && network != nil {
+		replaced = append(replaced, network)
+	}
+
+	return replaced
+}
+
+// splitVAppNetworkID splits the ID of a vcd_vapp_network or
+// vcd_vapp_org_network into the vApp HREF and the network name. The HREF
+// contains colons itself and ends in vapp-<uuid>, so the name follows the
+// first colon after that, and may contain colons of its own.
+func splitVAppNetworkID(id string) (string, string, error) {
+	i := strings.Index(id, "/vapp-")
+	if i >= 0 {
+		if j := strings.Index(id[i:], ":"); j >= 0 && i+j < len(id)-1 {
+			return id[:i+j], id[i+j+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("Unexpected vApp network ID %s, expected vapp-href:network-name", id)
+}
+
+// removedVAppNetworkNames returns the names of the networks in the old value of
+// organization_network or vapp_network that are gone from the new value.
+func removedVAppNetworkNames(o, n interface{}) []string {
+	names := func(list []interface{}) []string {
+		result := make([]string, 0, len(list))
+		for _, network := range list {
+			switch network := network.(type) {
+			case string:
+				result = append(result, network)
+			case map[string]interface{}:
+				result = append(result, network["name"].(string))
+			}
+		}
+		return result
+	}
+
+	current := make(map[string]bool)
+	for _, name := range names(n.([]interface{})) {
+		current[name] = true
+	}
+
+	var removed []string
+	for _, name := range names(o.([]interface{})) {
+		if !current[name] {
+			removed = append(removed, name)
+		}
+	}
+	return removed
+}
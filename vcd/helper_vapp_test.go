@@ -0,0 +1,106 @@
+package vcd
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplaceVAppNetworkConfiguration(t *testing.T) {
+	a := &VAppNetworkConfiguration{NetworkName: "a"}
+	b := &VAppNetworkConfiguration{NetworkName: "b"}
+	newA := &VAppNetworkConfiguration{NetworkName: "a", Description: "new"}
+
+	cases := []struct {
+		name     string
+		networks []*VAppNetworkConfiguration
+		network  string
+		new      *VAppNetworkConfiguration
+		expected []*VAppNetworkConfiguration
+	}{
+		{"add to none", nil, "a", a, []*VAppNetworkConfiguration{a}},
+		{"add", []*VAppNetworkConfiguration{b}, "a", a, []*VAppNetworkConfiguration{b, a}},
+		{"replace in place", []*VAppNetworkConfiguration{a, b}, "a", newA, []*VAppNetworkConfiguration{newA, b}},
+		{"remove", []*VAppNetworkConfiguration{a, b}, "a", nil, []*VAppNetworkConfiguration{b}},
+		{"remove last", []*VAppNetworkConfiguration{a}, "a", nil, []*VAppNetworkConfiguration{}},
+		{"remove missing", []*VAppNetworkConfiguration{b}, "a", nil, []*VAppNetworkConfiguration{b}},
+	}
+
+	for _, c := range cases {
+		actual := replaceVAppNetworkConfiguration(c.networks, c.network, c.new)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestSplitVAppNetworkID(t *testing.T) {
+	cases := []struct {
+		id       string
+		vappHREF string
+		name     string
+		err      bool
+	}{
+		{"https://vcd.example.com/api/vApp/vapp-1234:internal", "https://vcd.example.com/api/vApp/vapp-1234", "internal", false},
+		{"https://vcd.example.com/api/vApp/vapp-1234:web:internal", "https://vcd.example.com/api/vApp/vapp-1234", "web:internal", false},
+		{"https://vcd.example.com:443/api/vApp/vapp-1234:internal", "https://vcd.example.com:443/api/vApp/vapp-1234", "internal", false},
+		{"https://vcd.example.com/api/vApp/vapp-1234:", "", "", true},
+		{"https://vcd.example.com/api/vApp/vapp-1234", "", "", true},
+		{":internal", "", "", true},
+		{"internal", "", "", true},
+	}
+
+	for _, c := range cases {
+		vappHREF, name, err := splitVAppNetworkID(c.id)
+		if (err != nil) != c.err {
+			t.Errorf("%s: expected error %t, got %#v", c.id, c.err, err)
+			continue
+		}
+		if vappHREF != c.vappHREF || name != c.name {
+			t.Errorf("%s: expected %q and %q, got %q and %q", c.id, c.vappHREF, c.name, vappHREF, name)
+		}
+	}
+}
+
+func TestRemovedVAppNetworkNames(t *testing.T) {
+	cases := []struct {
+		name     string
+		o        []interface{}
+		n        []interface{}
+		expected []string
+	}{
+		{"organization networks",
+			[]interface{}{"a", "b", "c"}, []interface{}{"c", "a"},
+			[]string{"b"}},
+		{"vApp networks",
+			[]interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}},
+			[]interface{}{map[string]interface{}{"name": "b"}},
+			[]string{"a"}},
+		{"added only",
+			[]interface{}{"a"}, []interface{}{"a", "b"},
+			nil},
+		{"all removed",
+			[]interface{}{"a", "b"}, []interface{}{},
+			[]string{"a", "b"}},
+	}
+
+	for _, c := range cases {
+		actual := removedVAppNetworkNames(c.o, c.n)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestLeaseSettingsSectionRequest(t *testing.T) {
+	output, err := xml.Marshal(&LeaseSettingsSectionRequest{StorageLeaseInSeconds: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A deployment lease of 0, which never expires, has to be sent
+	if !strings.Contains(string(output), "<DeploymentLeaseInSeconds>0</DeploymentLeaseInSeconds>") {
+		t.Errorf("Expected the deployment lease in %s", output)
+	}
+}
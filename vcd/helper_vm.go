@@ -229,6 +229,116 @@ func configureVMWorkaround(d *schema.ResourceData, vm *govcd.VM, meta interface{
 	return nil
 }
 
+// The boot order of the VM is kept in the startup section of its vApp, so it
+// can only be changed by replacing that section.
+func configureVMStartup(d *schema.ResourceData, meta interface{}) error {
+	if !d.IsNewResource() &&
+		!d.HasChange("name") &&
+		!d.HasChange("startup_order") &&
+		!d.HasChange("start_action") &&
+		!d.HasChange("start_delay") &&
+		!d.HasChange("stop_action") &&
+		!d.HasChange("stop_delay") {
+		return nil
+	}
+
+	vappHREF := d.Get("vapp_href").(string)
+	section, err := getVAppStartupSection(vappHREF, meta)
+	if err != nil {
+		return err
+	}
+
+	oldName, newName := d.GetChange("name")
+
+	var item *StartupItem
+	for _, i := range section.Item {
+		if i.ID == newName.(string) || i.ID == oldName.(string) {
+			item = i
+			break
+		}
+	}
+
+	if item == nil {
+		// Without an order the VM is started after the others
+		item = &StartupItem{Order: nextStartupOrder(section)}
+		section.Item = append(section.Item, item)
+	}
+
+	item.ID = newName.(string)
+	if order, ok := d.GetOk("startup_order"); ok {
+		item.Order = order.(int)
+	}
+	item.StartAction = d.Get("start_action").(string)
+	item.StartDelay = d.Get("start_delay").(int)
+	item.StopAction = d.Get("stop_action").(string)
+	item.StopDelay = d.Get("stop_delay").(int)
+
+	return setVAppStartupSection(vappHREF, section, meta)
+}
+
+// nextStartupOrder returns the order following the last one of section.
+func nextStartupOrder(section *StartupSection) int {
+	if len(section.Item) == 0 {
+		return 0
+	}
+
+	next := 0
+	for _, item := range section.Item {
+		if item.Order >= next {
+			next = item.Order + 1
+		}
+	}
+	return next
+}
+
+func readVMStartup(d *schema.ResourceData, vm *govcd.VM, meta interface{}) error {
+	section, err := getVAppStartupSection(d.Get("vapp_href").(string), meta)
+	if err != nil {
+		return err
+	}
+
+	// A missing item, for example removed outside of Terraform, shows up as
+	// a change of the startup settings
+	item := &StartupItem{}
+	for _, i := range section.Item {
+		if i.ID == vm.VM.Name {
+			item = i
+			break
+		}
+	}
+
+	d.Set("startup_order", item.Order)
+	d.Set("start_action", item.StartAction)
+	d.Set("start_delay", item.StartDelay)
+	d.Set("stop_action", item.StopAction)
+	d.Set("stop_delay", item.StopDelay)
+
+	return nil
+}
+
+// removeVMStartup removes the item of the named VM from the startup section
+// of its vApp.
+func removeVMStartup(vappHREF, name string, meta interface{}) error {
+	section, err := getVAppStartupSection(vappHREF, meta)
+	if err != nil {
+		return err
+	}
+
+	items := make([]*StartupItem, 0, len(section.Item))
+	for _, item := range section.Item {
+		if item.ID != name {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == len(section.Item) {
+		return nil
+	}
+
+	section.Item = items
+	return setVAppStartupSection(vappHREF, section, meta)
+}
+
 func readVM(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
 
@@ -294,6 +404,11 @@ func readVM(d *schema.ResourceData, meta interface{}) error {
 	d.Set("nested_hypervisor_enabled", vm.VM.NestedHypervisorEnabled)
 	d.Set("href", vm.VM.HREF)
 
+	err = readVMStartup(d, &vm, meta)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,22 @@
+package vcd
+
+import "testing"
+
+func TestNextStartupOrder(t *testing.T) {
+	cases := []struct {
+		name     string
+		items    []*StartupItem
+		expected int
+	}{
+		{"empty", nil, 0},
+		{"first group", []*StartupItem{{Order: 0}, {Order: 0}}, 1},
+		{"after the last", []*StartupItem{{Order: 3}, {Order: 1}}, 4},
+	}
+
+	for _, c := range cases {
+		actual := nextStartupOrder(&StartupSection{Item: c.items})
+		if actual != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.name, c.expected, actual)
+		}
+	}
+}
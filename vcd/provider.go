@@ -65,14 +65,30 @@ func Provider() terraform.ResourceProvider {
 			},
 		},
 
+		DataSourcesMap: map[string]*schema.Resource{
+			"vcd_edgegateway_config": dataSourceVcdEdgeGatewayConfig(),
+		},
+
 		ResourcesMap: map[string]*schema.Resource{
-			"vcd_network":         resourceVcdNetwork(),
-			"vcd_vapp":            resourceVcdVApp(),
-			"vcd_firewall_rules":  resourceVcdFirewallRules(),
-			"vcd_dnat":            resourceVcdDNAT(),
-			"vcd_snat":            resourceVcdSNAT(),
-			"vcd_edgegateway_vpn": resourceVcdEdgeGatewayVpn(),
-			"vcd_vm":              resourceVcdVM(),
+			"vcd_network":                    resourceVcdNetwork(),
+			"vcd_vapp":                       resourceVcdVApp(),
+			"vcd_firewall_rule":              resourceVcdFirewallRule(),
+			"vcd_firewall_rules":             resourceVcdFirewallRules(),
+			"vcd_dnat":                       resourceVcdDNAT(),
+			"vcd_snat":                       resourceVcdSNAT(),
+			"vcd_nat_rule":                   resourceVcdNatRule(),
+			"vcd_nat_1to1":                   resourceVcdNat1to1(),
+			"vcd_edgegateway":                resourceVcdEdgeGateway(),
+			"vcd_edgegateway_config_restore": resourceVcdEdgeGatewayConfigRestore(),
+			"vcd_edgegateway_settings":       resourceVcdEdgeGatewaySettings(),
+			"vcd_edgegateway_vpn":            resourceVcdEdgeGatewayVpn(),
+			"vcd_vm":                         resourceVcdVM(),
+			"vcd_vapp_network":               resourceVcdVAppNetwork(),
+			"vcd_vapp_org_network":           resourceVcdVAppOrgNetwork(),
+			"vcd_edgegateway_static_route":   resourceVcdEdgeGatewayStaticRoute(),
+			"vcd_lb_pool":                    resourceVcdLBPool(),
+			"vcd_lb_virtual_server":          resourceVcdLBVirtualServer(),
+			"vcd_edgegateway_dhcp_pool":      resourceVcdEdgeGatewayDhcpPool(),
 		},
 
 		ConfigureFunc: providerConfigure,
@@ -2,17 +2,22 @@ package vcd
 
 import (
 	"fmt"
+	"log"
 
-	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
+	types "github.com/vCloud/govcloudair/types/v56"
 )
 
 func resourceVcdDNAT() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVcdDNATCreate,
+		Update: resourceVcdDNATUpdate,
 		Delete: resourceVcdDNATDelete,
 		Read:   resourceVcdDNATRead,
 
+		SchemaVersion: 1,
+		MigrateState:  resourceVcdDNATMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"edge_gateway": &schema.Schema{
 				Type:     schema.TypeString,
@@ -23,135 +28,125 @@ func resourceVcdDNAT() *schema.Resource {
 			"external_ip": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"port": &schema.Schema{
 				Type:     schema.TypeInt,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"translated_port": &schema.Schema{
 				Type:     schema.TypeInt,
 				Optional: true,
-				ForceNew: true,
+				Computed: true,
 			},
 
 			"internal_ip": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
 			},
 		},
 	}
 }
 
 func resourceVcdDNATCreate(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	// Multiple VCD components need to run operations on the Edge Gateway, as
-	// the edge gatway will throw back an error if it is already performing an
-	// operation we must wait until we can aquire a lock on the client
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
 	portString := getPortString(d.Get("port").(int))
 	translatedPortString := portString // default
 	if d.Get("translated_port").(int) > 0 {
 		translatedPortString = getPortString(d.Get("translated_port").(int))
 	}
 
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	rule := &types.NatRule{
+		RuleType:  "DNAT",
+		IsEnabled: d.Get("enabled").(bool),
+		GatewayNatRule: &types.GatewayNatRule{
+			OriginalIP:     d.Get("external_ip").(string),
+			OriginalPort:   portString,
+			TranslatedIP:   d.Get("internal_ip").(string),
+			TranslatedPort: translatedPortString,
+			Protocol:       "tcp",
+		},
+	}
 
+	id, err := addNatMapping(d.Get("edge_gateway").(string), rule,
+		dnatRuleMatcher(d.Get("external_ip").(string), portString, d.Get("internal_ip").(string), translatedPortString), meta)
 	if err != nil {
-		return fmt.Errorf("Unable to find edge gateway: %#v", err)
+		return fmt.Errorf("Error adding DNAT rule: %#v", err)
 	}
 
-	// Creating a loop to offer further protection from the edge gateway erroring
-	// due to being busy eg another person is using another client so wouldn't be
-	// constrained by out lock. If the edge gateway reurns with a busy error, wait
-	// 3 seconds and then try again. Continue until a non-busy error or success
-
-	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		task, err := edgeGateway.AddNATPortMapping("DNAT",
-			d.Get("external_ip").(string),
-			portString,
-			d.Get("internal_ip").(string),
-			translatedPortString)
-		if err != nil {
-			return resource.RetryableError(
-				fmt.Errorf("Error setting DNAT rules: %#v", err))
-		}
-
-		return resource.RetryableError(task.WaitTaskCompletion())
-	})
+	d.SetId(id)
+
+	return resourceVcdDNATRead(d, meta)
+}
+
+func resourceVcdDNATUpdate(d *schema.ResourceData, meta interface{}) error {
+	portString := getPortString(d.Get("port").(int))
+	translatedPortString := portString // default
+	if d.Get("translated_port").(int) > 0 {
+		translatedPortString = getPortString(d.Get("translated_port").(int))
+	}
 
+	err := updateNatRule(d.Get("edge_gateway").(string), d.Id(), func(rule *types.NatRule) {
+		rule.IsEnabled = d.Get("enabled").(bool)
+		rule.GatewayNatRule.OriginalIP = d.Get("external_ip").(string)
+		rule.GatewayNatRule.OriginalPort = portString
+		rule.GatewayNatRule.TranslatedIP = d.Get("internal_ip").(string)
+		rule.GatewayNatRule.TranslatedPort = translatedPortString
+	}, meta)
 	if err != nil {
-		return fmt.Errorf("Error completing tasks: %#v", err)
+		return fmt.Errorf("Error updating DNAT rule %s: %#v", d.Id(), err)
 	}
 
-	d.SetId(d.Get("external_ip").(string) + ":" + portString + " > " + d.Get("internal_ip").(string) + ":" + translatedPortString)
-	return nil
+	return resourceVcdDNATRead(d, meta)
+}
+
+// dnatRuleMatcher matches DNAT rules translating externalIP:port to
+// internalIP:translatedPort.
+func dnatRuleMatcher(externalIP, port, internalIP, translatedPort string) func(*types.NatRule) bool {
+	return func(r *types.NatRule) bool {
+		return r.RuleType == "DNAT" &&
+			r.GatewayNatRule != nil &&
+			r.GatewayNatRule.OriginalIP == externalIP &&
+			r.GatewayNatRule.OriginalPort == port &&
+			r.GatewayNatRule.TranslatedIP == internalIP &&
+			r.GatewayNatRule.TranslatedPort == translatedPort
+	}
 }
 
 func resourceVcdDNATRead(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	e, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	e, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
 
 	if err != nil {
 		return fmt.Errorf("Unable to find edge gateway: %#v", err)
 	}
 
-	var found bool
-
-	for _, r := range e.EdgeGateway.Configuration.EdgeGatewayServiceConfiguration.NatService.NatRule {
-		if r.RuleType == "DNAT" &&
-			r.GatewayNatRule.OriginalIP == d.Get("external_ip").(string) &&
-			r.GatewayNatRule.OriginalPort == getPortString(d.Get("port").(int)) {
-			found = true
-			d.Set("internal_ip", r.GatewayNatRule.TranslatedIP)
-		}
-	}
-
-	if !found {
+	r := findNatRule(edgeGatewayNatRules(e), d.Id())
+	if r == nil || r.RuleType != "DNAT" || r.GatewayNatRule == nil {
+		log.Printf("[DEBUG] Unable to find DNAT rule %s. Removing from tfstate", d.Id())
 		d.SetId("")
+		return nil
 	}
 
+	d.Set("external_ip", r.GatewayNatRule.OriginalIP)
+	d.Set("port", getNumericPort(r.GatewayNatRule.OriginalPort))
+	d.Set("internal_ip", r.GatewayNatRule.TranslatedIP)
+	d.Set("translated_port", getNumericPort(r.GatewayNatRule.TranslatedPort))
+	d.Set("enabled", r.IsEnabled)
+
 	return nil
 }
 
 func resourceVcdDNATDelete(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	// Multiple VCD components need to run operations on the Edge Gateway, as
-	// the edge gatway will throw back an error if it is already performing an
-	// operation we must wait until we can aquire a lock on the client
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
-	portString := getPortString(d.Get("port").(int))
-	translatedPortString := portString // default
-	if d.Get("translated_port").(int) > 0 {
-		translatedPortString = getPortString(d.Get("translated_port").(int))
-	}
-
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
-
+	err := removeNatRule(d.Get("edge_gateway").(string), d.Id(), meta)
 	if err != nil {
-		return fmt.Errorf("Unable to find edge gateway: %#v", err)
-	}
-	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		task, err := edgeGateway.RemoveNATPortMapping("DNAT",
-			d.Get("external_ip").(string),
-			portString,
-			d.Get("internal_ip").(string),
-			translatedPortString)
-		if err != nil {
-			return resource.RetryableError(
-				fmt.Errorf("Error setting DNAT rules: %#v", err))
-		}
-
-		return resource.RetryableError(task.WaitTaskCompletion())
-	})
-	if err != nil {
-		return fmt.Errorf("Error completing tasks: %#v", err)
+		return fmt.Errorf("Error removing DNAT rule %s: %#v", d.Id(), err)
 	}
+
 	return nil
 }
@@ -0,0 +1,57 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func resourceVcdDNATMigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found vcd_dnat State v0; migrating to v1")
+		return migrateVcdDNATStateV0toV1(is, meta)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+// migrateVcdDNATStateV0toV1 replaces the "external:port > internal:port"
+// string, which was used as the ID, with the Id of the NAT rule.
+func migrateVcdDNATStateV0toV1(is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	port, err := strconv.Atoi(is.Attributes["port"])
+	if err != nil {
+		return is, fmt.Errorf("Error parsing port %s: %#v", is.Attributes["port"], err)
+	}
+	portString := getPortString(port)
+	translatedPortString := portString
+	if translatedPort, _ := strconv.Atoi(is.Attributes["translated_port"]); translatedPort > 0 {
+		translatedPortString = getPortString(translatedPort)
+	}
+
+	edgeGateway, err := getEdgeGateway(is.Attributes["edge_gateway"], meta)
+	if err != nil {
+		return is, fmt.Errorf("Error finding edge gateway %s: %#v", is.Attributes["edge_gateway"], err)
+	}
+
+	id := findNatRuleID(edgeGatewayNatRules(edgeGateway),
+		dnatRuleMatcher(is.Attributes["external_ip"], portString, is.Attributes["internal_ip"], translatedPortString))
+	if id == "" {
+		// Read removes the rule from state, so it gets created again
+		log.Printf("[DEBUG] Unable to find DNAT rule for %s; keeping its ID", is.ID)
+	} else {
+		is.ID = id
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
@@ -0,0 +1,75 @@
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func TestVcdDNATMigrateFindRule(t *testing.T) {
+	snat := &types.NatRule{ID: "65537", RuleType: "SNAT", GatewayNatRule: &types.GatewayNatRule{OriginalIP: "203.0.113.10", TranslatedIP: "10.10.102.50"}}
+	dnat := &types.NatRule{ID: "65538", RuleType: "DNAT", GatewayNatRule: &types.GatewayNatRule{
+		OriginalIP: "203.0.113.10", OriginalPort: "80", TranslatedIP: "10.10.102.50", TranslatedPort: "8080"}}
+	rules := []*types.NatRule{snat, dnat}
+
+	cases := map[string]struct {
+		Port           string
+		TranslatedPort string
+		Expected       string
+	}{
+		"found":     {"80", "8080", "65538"},
+		"not_found": {"80", "80", ""},
+	}
+
+	for tn, tc := range cases {
+		id := findNatRuleID(rules, dnatRuleMatcher("203.0.113.10", tc.Port, "10.10.102.50", tc.TranslatedPort))
+		if id != tc.Expected {
+			t.Fatalf("bad ID for %s: expected %q, got %q", tn, tc.Expected, id)
+		}
+	}
+}
+
+func TestVcdDNATMigrateState_badPort(t *testing.T) {
+	is := &terraform.InstanceState{
+		ID: "203.0.113.10:http > 10.10.102.50:http",
+		Attributes: map[string]string{
+			"edge_gateway": "Edge Gateway Name",
+			"external_ip":  "203.0.113.10",
+			"port":         "http",
+			"internal_ip":  "10.10.102.50",
+		},
+	}
+
+	// the port is parsed before the edge gateway is looked up
+	_, err := resourceVcdDNATMigrateState(0, is, nil)
+	if err == nil {
+		t.Fatalf("expected an error for a port that is not a number")
+	}
+}
+
+func TestVcdDNATMigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+
+	// should handle nil
+	is, err := resourceVcdDNATMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	is, err = resourceVcdDNATMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+
+	// should refuse unknown versions
+	_, err = resourceVcdDNATMigrateState(1, &terraform.InstanceState{}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown schema version")
+	}
+}
@@ -0,0 +1,363 @@
+package vcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vCloud/govcloudair"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdEdgeGateway() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdEdgeGatewayCreate,
+		Update: resourceVcdEdgeGatewayUpdate,
+		Read:   resourceVcdEdgeGatewayRead,
+		Delete: resourceVcdEdgeGatewayDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"backing_config": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "compact",
+				ValidateFunc: validation.StringInSlice([]string{
+					"compact",
+					"full",
+				}, false),
+			},
+
+			"ha_enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"use_default_route_for_dns_relay": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"external_network": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"gateway": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: ValidateIPv4(),
+						},
+
+						"netmask": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: ValidateIPv4(),
+						},
+
+						// Address of the edge gateway on the external network,
+						// assigned by vCD when not set
+						"ip_address": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+
+						"suballocate_pool": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"start_address": &schema.Schema{
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: ValidateIPv4(),
+									},
+
+									"end_address": &schema.Schema{
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: ValidateIPv4(),
+									},
+								},
+							},
+						},
+
+						"use_for_default_route": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+
+						"apply_rate_limit": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+
+						// Gbps
+						"incoming_rate_limit": &schema.Schema{
+							Type:     schema.TypeFloat,
+							Optional: true,
+						},
+
+						// Gbps
+						"outgoing_rate_limit": &schema.Schema{
+							Type:     schema.TypeFloat,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVcdEdgeGatewayCreate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	interfaces, err := expandEdgeGatewayUplinks(d, meta)
+	if err != nil {
+		return err
+	}
+
+	edgeGateway := &EdgeGateway{
+		Xmlns:       "http://www.vmware.com/vcloud/v1.5",
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Configuration: &GatewayConfiguration{
+			GatewayBackingConfig:       d.Get("backing_config").(string),
+			GatewayInterfaces:          &types.GatewayInterfaces{GatewayInterface: interfaces},
+			HaEnabled:                  d.Get("ha_enabled").(bool),
+			UseDefaultRouteForDNSRelay: d.Get("use_default_route_for_dns_relay").(bool),
+		},
+	}
+
+	output, err := xml.MarshalIndent(edgeGateway, "  ", "    ")
+	if err != nil {
+		return fmt.Errorf("Error marshaling edge gateway: %s", err)
+	}
+
+	pathArr := strings.Split(vcdClient.OrgVdc.Vdc.HREF, "/")
+	s, err := url.ParseRequestURI(vcdClient.OrgVdc.Vdc.HREF)
+	if err != nil {
+		return fmt.Errorf("Error parsing VDC href %s: %s", vcdClient.OrgVdc.Vdc.HREF, err)
+	}
+	s.Path = "/api/admin/vdc/" + pathArr[len(pathArr)-1] + "/edgeGateways"
+
+	log.Printf("[DEBUG] Creating edge gateway %s", edgeGateway.Name)
+
+	req := vcdClient.Client.NewRequest(map[string]string{}, "POST", *s, strings.NewReader(xml.Header+string(output)))
+	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGateway+xml")
+
+	created := &EdgeGateway{}
+	err = doRequest(&vcdClient.Client, req, created)
+	if err != nil {
+		return fmt.Errorf("Error creating edge gateway: %#v", err)
+	}
+
+	d.SetId(created.HREF)
+
+	if created.Tasks != nil {
+		for _, t := range created.Tasks.Task {
+			task := govcloudair.NewTask(&vcdClient.Client)
+			task.Task = t
+			if err := task.WaitTaskCompletion(); err != nil {
+				return fmt.Errorf("Error creating edge gateway: %#v", err)
+			}
+		}
+	}
+
+	return resourceVcdEdgeGatewayRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := updateEdgeGatewayConfiguration(d.Id(), func(edgeGateway *EdgeGateway) error {
+		uplinks, err := expandEdgeGatewayUplinks(d, meta)
+		if err != nil {
+			return err
+		}
+
+		// Internal interfaces belong to the org networks routed by the
+		// gateway and are kept
+		interfaces := uplinks
+		for _, gatewayInterface := range edgeGateway.Configuration.GatewayInterfaces.GatewayInterface {
+			if !strings.EqualFold(gatewayInterface.InterfaceType, "uplink") {
+				interfaces = append(interfaces, gatewayInterface)
+			}
+		}
+
+		edgeGateway.Description = d.Get("description").(string)
+		edgeGateway.Configuration.HaEnabled = d.Get("ha_enabled").(bool)
+		edgeGateway.Configuration.UseDefaultRouteForDNSRelay = d.Get("use_default_route_for_dns_relay").(bool)
+		edgeGateway.Configuration.GatewayInterfaces.GatewayInterface = interfaces
+
+		return nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating edge gateway: %#v", err)
+	}
+
+	return resourceVcdEdgeGatewayRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	edgeGateway := &EdgeGateway{}
+	err := getEntity(d.Id(), edgeGateway, &vcdClient.Client)
+	if err != nil {
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Unable to find edge gateway. Removing from tfstate")
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading edge gateway: %#v", err)
+	}
+
+	d.Set("name", edgeGateway.Name)
+	d.Set("description", edgeGateway.Description)
+	if edgeGateway.Configuration == nil {
+		return nil
+	}
+
+	d.Set("backing_config", edgeGateway.Configuration.GatewayBackingConfig)
+	d.Set("ha_enabled", edgeGateway.Configuration.HaEnabled)
+	d.Set("use_default_route_for_dns_relay", edgeGateway.Configuration.UseDefaultRouteForDNSRelay)
+
+	if edgeGateway.Configuration.GatewayInterfaces != nil {
+		err = d.Set("external_network", flattenEdgeGatewayUplinks(edgeGateway.Configuration.GatewayInterfaces.GatewayInterface))
+		if err != nil {
+			return fmt.Errorf("Error setting external_network: %#v", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVcdEdgeGatewayDelete(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(d.Id())
+	defer vcdClient.locks.unlock(d.Id())
+
+	err := retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+		s, err := url.ParseRequestURI(d.Id())
+		if err != nil {
+			return govcloudair.Task{}, fmt.Errorf("Error parsing edge gateway href %s: %s", d.Id(), err)
+		}
+
+		req := vcdClient.Client.NewRequest(map[string]string{}, "DELETE", *s, nil)
+
+		task := govcloudair.NewTask(&vcdClient.Client)
+		err = doRequest(&vcdClient.Client, req, task.Task)
+		if err != nil {
+			return govcloudair.Task{}, err
+		}
+
+		return *task, nil
+	})
+	if err != nil {
+		return fmt.Errorf("Error deleting edge gateway: %#v", err)
+	}
+
+	return nil
+}
+
+func expandEdgeGatewayUplinks(d *schema.ResourceData, meta interface{}) ([]*types.GatewayInterface, error) {
+	configured := d.Get("external_network").([]interface{})
+	interfaces := make([]*types.GatewayInterface, 0, len(configured))
+
+	for _, raw := range configured {
+		data := raw.(map[string]interface{})
+
+		network, err := findExternalNetwork(data["name"].(string), meta)
+		if err != nil {
+			return nil, err
+		}
+
+		subnet := &types.SubnetParticipation{
+			Gateway:   data["gateway"].(string),
+			Netmask:   data["netmask"].(string),
+			IPAddress: data["ip_address"].(string),
+		}
+		if pools := data["suballocate_pool"].([]interface{}); len(pools) > 0 {
+			ipRanges := expandIPRange(pools)
+			subnet.IPRanges = &ipRanges
+		}
+
+		interfaces = append(interfaces, &types.GatewayInterface{
+			DisplayName:         network.Name,
+			Network:             network,
+			InterfaceType:       "uplink",
+			SubnetParticipation: subnet,
+			UseForDefaultRoute:  data["use_for_default_route"].(bool),
+			ApplyRateLimit:      data["apply_rate_limit"].(bool),
+			InRateLimit:         data["incoming_rate_limit"].(float64),
+			OutRateLimit:        data["outgoing_rate_limit"].(float64),
+		})
+	}
+
+	return interfaces, nil
+}
+
+func flattenEdgeGatewayUplinks(interfaces []*types.GatewayInterface) []interface{} {
+	result := make([]interface{}, 0, len(interfaces))
+
+	for _, gatewayInterface := range interfaces {
+		if !strings.EqualFold(gatewayInterface.InterfaceType, "uplink") || gatewayInterface.Network == nil {
+			continue
+		}
+
+		data := map[string]interface{}{
+			"name":                  gatewayInterface.Network.Name,
+			"use_for_default_route": gatewayInterface.UseForDefaultRoute,
+			"apply_rate_limit":      gatewayInterface.ApplyRateLimit,
+			"incoming_rate_limit":   gatewayInterface.InRateLimit,
+			"outgoing_rate_limit":   gatewayInterface.OutRateLimit,
+		}
+
+		if subnet := gatewayInterface.SubnetParticipation; subnet != nil {
+			data["gateway"] = subnet.Gateway
+			data["netmask"] = subnet.Netmask
+			data["ip_address"] = subnet.IPAddress
+
+			pools := []interface{}{}
+			if subnet.IPRanges != nil {
+				for _, ipRange := range subnet.IPRanges.IPRange {
+					pools = append(pools, map[string]interface{}{
+						"start_address": ipRange.StartAddress,
+						"end_address":   ipRange.EndAddress,
+					})
+				}
+			}
+			data["suballocate_pool"] = pools
+		}
+
+		result = append(result, data)
+	}
+
+	return result
+}
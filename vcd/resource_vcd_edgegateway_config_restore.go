@@ -0,0 +1,96 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/structure"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceVcdEdgeGatewayConfigRestore() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdEdgeGatewayConfigRestoreCreate,
+		Update: resourceVcdEdgeGatewayConfigRestoreUpdate,
+		Read:   resourceVcdEdgeGatewayConfigRestoreRead,
+		Delete: resourceVcdEdgeGatewayConfigRestoreDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			// A document exported by the vcd_edgegateway_config data source
+			"config": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				Sensitive:        true,
+				ValidateFunc:     validation.ValidateJsonString,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
+			},
+		},
+	}
+}
+
+func resourceVcdEdgeGatewayConfigRestoreCreate(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := restoreEdgeGatewayServiceConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(edgeGateway)
+
+	return resourceVcdEdgeGatewayConfigRestoreRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayConfigRestoreUpdate(d *schema.ResourceData, meta interface{}) error {
+	_, err := restoreEdgeGatewayServiceConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return resourceVcdEdgeGatewayConfigRestoreRead(d, meta)
+}
+
+// resourceVcdEdgeGatewayConfigRestoreRead only checks that the edge gateway
+// still exists, the services are free to change after the restore.
+func resourceVcdEdgeGatewayConfigRestoreRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	_, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	if err != nil {
+		log.Printf("[DEBUG] Unable to find edge gateway. Removing from tfstate")
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+// resourceVcdEdgeGatewayConfigRestoreDelete leaves the restored services on
+// the edge gateway.
+func resourceVcdEdgeGatewayConfigRestoreDelete(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+// restoreEdgeGatewayServiceConfiguration pushes the services of the document
+// in config to the edge gateway and returns its HREF. Services missing from
+// the document are left as they are.
+func restoreEdgeGatewayServiceConfiguration(d *schema.ResourceData, meta interface{}) (string, error) {
+	configuration, err := expandEdgeGatewayServiceConfiguration(d.Get("config").(string))
+	if err != nil {
+		return "", fmt.Errorf("Error parsing edge gateway services: %#v", err)
+	}
+
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return configuration, nil
+	}, meta)
+	if err != nil {
+		return "", fmt.Errorf("Error restoring edge gateway services: %#v", err)
+	}
+
+	return edgeGateway.HREF, nil
+}
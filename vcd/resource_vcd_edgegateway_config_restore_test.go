@@ -0,0 +1,80 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func testAccCheckVcdEdgeGatewayConfigRestoreExists(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if rs.Primary.ID != edgeGateway.HREF {
+			return fmt.Errorf("Expected the ID %s, got %s", edgeGateway.HREF, rs.Primary.ID)
+		}
+
+		// The rule exported before the restore is still there
+		for _, rule := range firewallRulesOf(edgeGatewayServices(edgeGateway).FirewallService) {
+			if rule.Description == "terraform-acc-config-restore" {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("Restored firewall rule not found")
+	}
+}
+
+func TestAccVcdEdgeGatewayConfigRestore_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdFirewallRuleDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayConfigRestore_basic, edgeGateway, edgeGateway, edgeGateway),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVcdEdgeGatewayConfigRestoreExists("vcd_edgegateway_config_restore.test"),
+					resource.TestCheckResourceAttrPair(
+						"vcd_edgegateway_config_restore.test", "config", "data.vcd_edgegateway_config.test", "config"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdEdgeGatewayConfigRestore_basic = `
+resource "vcd_firewall_rule" "test" {
+  edge_gateway     = "%s"
+  description      = "terraform-acc-config-restore"
+  policy           = "allow"
+  protocol         = "tcp"
+  destination_port = "22"
+  destination_ip   = "10.10.102.50"
+  source_port      = "any"
+  source_ip        = "any"
+}
+
+data "vcd_edgegateway_config" "test" {
+  edge_gateway = "%s"
+  depends_on   = ["vcd_firewall_rule.test"]
+}
+
+resource "vcd_edgegateway_config_restore" "test" {
+  edge_gateway = "%s"
+  config       = "${data.vcd_edgegateway_config.test.config}"
+}
+`
@@ -0,0 +1,295 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdEdgeGatewayDhcpPool() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdEdgeGatewayDhcpPoolCreate,
+		Update: resourceVcdEdgeGatewayDhcpPoolUpdate,
+		Read:   resourceVcdEdgeGatewayDhcpPoolRead,
+		Delete: resourceVcdEdgeGatewayDhcpPoolDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVcdEdgeGatewayDhcpPoolImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"network": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"start_address": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: ValidateIPv4(),
+			},
+
+			"end_address": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: ValidateIPv4(),
+			},
+
+			"default_lease_time": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  3600,
+			},
+
+			"max_lease_time": &schema.Schema{
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  7200,
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+func resourceVcdEdgeGatewayDhcpPoolCreate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	network, err := vcdClient.OrgVdc.FindVDCNetwork(d.Get("network").(string))
+	if err != nil {
+		return fmt.Errorf("Error finding network: %#v", err)
+	}
+
+	pool := expandEdgeGatewayDhcpPool(d, network.OrgVDCNetwork)
+
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		if findEdgeGatewayDhcpPool(services.GatewayDhcpService, network.OrgVDCNetwork.HREF, pool.LowIPAddress) != nil {
+			return nil, fmt.Errorf("Edge gateway %s already has a DHCP pool starting at %s on network %s", edgeGateway.Name, pool.LowIPAddress, network.OrgVDCNetwork.Name)
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			GatewayDhcpService: replaceEdgeGatewayDhcpPool(services.GatewayDhcpService, network.OrgVDCNetwork.HREF, pool.LowIPAddress, pool),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding DHCP pool: %#v", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s:%s-%s", edgeGateway.HREF, network.OrgVDCNetwork.Name, pool.LowIPAddress, pool.HighIPAddress))
+
+	return resourceVcdEdgeGatewayDhcpPoolRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayDhcpPoolUpdate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	network, err := vcdClient.OrgVdc.FindVDCNetwork(d.Get("network").(string))
+	if err != nil {
+		return fmt.Errorf("Error finding network: %#v", err)
+	}
+
+	pool := expandEdgeGatewayDhcpPool(d, network.OrgVDCNetwork)
+
+	_, err = updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			GatewayDhcpService: replaceEdgeGatewayDhcpPool(services.GatewayDhcpService, network.OrgVDCNetwork.HREF, pool.LowIPAddress, pool),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating DHCP pool: %#v", err)
+	}
+
+	return resourceVcdEdgeGatewayDhcpPoolRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayDhcpPoolRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	network, err := vcdClient.OrgVdc.FindVDCNetwork(d.Get("network").(string))
+	if err != nil {
+		log.Printf("[DEBUG] Network of DHCP pool no longer exists. Removing from tfstate")
+		d.SetId("")
+		return nil
+	}
+
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	pool := findEdgeGatewayDhcpPool(edgeGatewayServices(edgeGateway).GatewayDhcpService,
+		network.OrgVDCNetwork.HREF, d.Get("start_address").(string))
+	if pool == nil {
+		log.Printf("[DEBUG] Unable to find DHCP pool. Removing from tfstate")
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("start_address", pool.LowIPAddress)
+	d.Set("end_address", pool.HighIPAddress)
+	d.Set("default_lease_time", pool.DefaultLeaseTime)
+	d.Set("max_lease_time", pool.MaxLeaseTime)
+	d.Set("enabled", pool.IsEnabled)
+
+	return nil
+}
+
+func resourceVcdEdgeGatewayDhcpPoolDelete(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	network, err := vcdClient.OrgVdc.FindVDCNetwork(d.Get("network").(string))
+	if err != nil {
+		return fmt.Errorf("Error finding network: %#v", err)
+	}
+
+	_, err = updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			GatewayDhcpService: replaceEdgeGatewayDhcpPool(services.GatewayDhcpService, network.OrgVDCNetwork.HREF, d.Get("start_address").(string), nil),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing DHCP pool: %#v", err)
+	}
+
+	return nil
+}
+
+// resourceVcdEdgeGatewayDhcpPoolImport imports a pool given by its resource
+// ID, edge-gateway-href:network-name:start-address-end-address, or as
+// edge-gateway-name:network-name:start-address.
+func resourceVcdEdgeGatewayDhcpPoolImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	vcdClient := meta.(*VCDClient)
+
+	gateway, networkName, startAddress, err := splitEdgeGatewayDhcpPoolID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	edgeGateway := &EdgeGateway{}
+	if strings.Contains(gateway, "://") {
+		err = getEntity(gateway, edgeGateway, &vcdClient.Client)
+	} else {
+		edgeGateway, err = getEdgeGateway(gateway, meta)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	network, err := vcdClient.OrgVdc.FindVDCNetwork(networkName)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding network: %#v", err)
+	}
+
+	pool := findEdgeGatewayDhcpPool(edgeGatewayServices(edgeGateway).GatewayDhcpService, network.OrgVDCNetwork.HREF, startAddress)
+	if pool == nil {
+		return nil, fmt.Errorf("Edge gateway %s has no DHCP pool starting at %s on network %s", edgeGateway.Name, startAddress, networkName)
+	}
+
+	d.Set("edge_gateway", edgeGateway.Name)
+	d.Set("network", network.OrgVDCNetwork.Name)
+	d.Set("start_address", pool.LowIPAddress)
+	d.SetId(fmt.Sprintf("%s:%s:%s-%s", edgeGateway.HREF, network.OrgVDCNetwork.Name, pool.LowIPAddress, pool.HighIPAddress))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// splitEdgeGatewayDhcpPoolID splits an imported DHCP pool ID into the edge
+// gateway name or HREF, the network name and the start address of the pool.
+// The ID is split from the right as the HREF itself contains colons.
+func splitEdgeGatewayDhcpPoolID(id string) (string, string, string, error) {
+	parts := strings.Split(id, ":")
+	if len(parts) < 3 {
+		return "", "", "", fmt.Errorf("Unexpected DHCP pool ID %s, expected edge-gateway-name:network-name:start-address", id)
+	}
+
+	addresses := parts[len(parts)-1]
+	if i := strings.Index(addresses, "-"); i >= 0 {
+		addresses = addresses[:i]
+	}
+
+	gateway := strings.Join(parts[:len(parts)-2], ":")
+	network := parts[len(parts)-2]
+	if gateway == "" || network == "" || addresses == "" {
+		return "", "", "", fmt.Errorf("Unexpected DHCP pool ID %s, expected edge-gateway-name:network-name:start-address", id)
+	}
+
+	return gateway, network, addresses, nil
+}
+
+func expandEdgeGatewayDhcpPool(d *schema.ResourceData, network *types.OrgVDCNetwork) *types.DhcpPoolService {
+	return &types.DhcpPoolService{
+		IsEnabled: d.Get("enabled").(bool),
+		Network: &types.Reference{
+			HREF: network.HREF,
+			Name: network.Name,
+		},
+		DefaultLeaseTime: d.Get("default_lease_time").(int),
+		MaxLeaseTime:     d.Get("max_lease_time").(int),
+		LowIPAddress:     d.Get("start_address").(string),
+		HighIPAddress:    d.Get("end_address").(string),
+	}
+}
+
+// isDhcpPoolOf reports whether pool belongs to the network at networkHREF,
+// see isNetworkDhcpPool, and starts at startAddress.
+func isDhcpPoolOf(pool *types.DhcpPoolService, networkHREF, startAddress string) bool {
+	return isNetworkDhcpPool(pool, networkHREF) && pool.LowIPAddress == startAddress
+}
+
+func findEdgeGatewayDhcpPool(service *types.GatewayDhcpService, networkHREF, startAddress string) *types.DhcpPoolService {
+	if service == nil {
+		return nil
+	}
+
+	for _, pool := range service.Pool {
+		if isDhcpPoolOf(pool, networkHREF, startAddress) {
+			return pool
+		}
+	}
+
+	return nil
+}
+
+// replaceEdgeGatewayDhcpPool returns the DHCP service with the pool of the
+// network starting at startAddress replaced by pool, or removed when pool is
+// nil. The service is enabled whenever it has pools.
+func replaceEdgeGatewayDhcpPool(service *types.GatewayDhcpService, networkHREF, startAddress string, pool *types.DhcpPoolService) *types.GatewayDhcpService {
+	newService := &types.GatewayDhcpService{}
+
+	found := false
+	if service != nil {
+		for _, existing := range service.Pool {
+			if !isDhcpPoolOf(existing, networkHREF, startAddress) {
+				newService.Pool = append(newService.Pool, existing)
+				continue
+			}
+			found = true
+			if pool != nil {
+				newService.Pool = append(newService.Pool, pool)
+			}
+		}
+	}
+
+	if !found && pool != nil {
+		newService.Pool = append(newService.Pool, pool)
+	}
+
+	newService.IsEnabled = len(newService.Pool) > 0 || (service != nil && service.IsEnabled)
+
+	return newService
+}
@@ -0,0 +1,159 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func TestReplaceEdgeGatewayDhcpPool(t *testing.T) {
+	networkHREF := "https://vcd.example.com/api/network/1111"
+	adminHREF := "https://vcd.example.com/api/admin/network/1111"
+	otherHREF := "https://vcd.example.com/api/admin/network/2222"
+
+	a := &types.DhcpPoolService{Network: &types.Reference{HREF: adminHREF}, LowIPAddress: "10.1.0.100", HighIPAddress: "10.1.0.150"}
+	b := &types.DhcpPoolService{Network: &types.Reference{HREF: adminHREF}, LowIPAddress: "10.1.0.200", HighIPAddress: "10.1.0.250"}
+	other := &types.DhcpPoolService{Network: &types.Reference{HREF: otherHREF}, LowIPAddress: "10.1.0.100"}
+	newA := &types.DhcpPoolService{Network: &types.Reference{HREF: networkHREF}, LowIPAddress: "10.1.0.100", HighIPAddress: "10.1.0.199"}
+
+	cases := []struct {
+		name         string
+		service      *types.GatewayDhcpService
+		startAddress string
+		pool         *types.DhcpPoolService
+		expected     *types.GatewayDhcpService
+	}{
+		{"add to missing service", nil, "10.1.0.100", newA,
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{newA}}},
+		{"add next to other pools", &types.GatewayDhcpService{Pool: []*types.DhcpPoolService{b, other}}, "10.1.0.100", newA,
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{b, other, newA}}},
+		{"replace by admin HREF in place", &types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{a, b}}, "10.1.0.100", newA,
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{newA, b}}},
+		{"remove", &types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other, a, b}}, "10.1.0.100", nil,
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other, b}}},
+		{"remove last keeps the service state", &types.GatewayDhcpService{Pool: []*types.DhcpPoolService{a}}, "10.1.0.100", nil,
+			&types.GatewayDhcpService{}},
+		{"remove missing", &types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other}}, "10.1.0.100", nil,
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other}}},
+	}
+
+	for _, c := range cases {
+		actual := replaceEdgeGatewayDhcpPool(c.service, networkHREF, c.startAddress, c.pool)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestSplitEdgeGatewayDhcpPoolID(t *testing.T) {
+	cases := []struct {
+		id      string
+		gateway string
+		network string
+		start   string
+		err     bool
+	}{
+		{"https://vcd.example.com/api/admin/edgeGateway/1234:my-net:10.10.0.100-10.10.0.150",
+			"https://vcd.example.com/api/admin/edgeGateway/1234", "my-net", "10.10.0.100", false},
+		{"Edge Gateway Name:my-net:10.10.0.100", "Edge Gateway Name", "my-net", "10.10.0.100", false},
+		{"my-net:10.10.0.100", "", "", "", true},
+		{"Edge Gateway Name::10.10.0.100", "", "", "", true},
+	}
+
+	for _, c := range cases {
+		gateway, network, start, err := splitEdgeGatewayDhcpPoolID(c.id)
+		if (err != nil) != c.err {
+			t.Errorf("%s: expected error %t, got %#v", c.id, c.err, err)
+			continue
+		}
+		if gateway != c.gateway || network != c.network || start != c.start {
+			t.Errorf("%s: expected %q, %q and %q, got %q, %q and %q", c.id, c.gateway, c.network, c.start, gateway, network, start)
+		}
+	}
+}
+
+func testAccCheckVcdEdgeGatewayDhcpPoolDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_edgegateway_dhcp_pool" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		service := edgeGatewayServices(edgeGateway).GatewayDhcpService
+		if service == nil {
+			continue
+		}
+		for _, pool := range service.Pool {
+			if pool.LowIPAddress == rs.Primary.Attributes["start_address"] {
+				return fmt.Errorf("DHCP pool starting at %s still exists", pool.LowIPAddress)
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdEdgeGatewayDhcpPool_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdEdgeGatewayDhcpPoolDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayDhcpPool_basic, edgeGateway, edgeGateway, 3600),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_dhcp_pool.test", "end_address", "10.10.103.150"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_dhcp_pool.test", "default_lease_time", "3600"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_dhcp_pool.test", "enabled", "true"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayDhcpPool_basic, edgeGateway, edgeGateway, 1800),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_dhcp_pool.test", "default_lease_time", "1800"),
+				),
+			},
+			resource.TestStep{
+				ResourceName:      "vcd_edgegateway_dhcp_pool.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+const testAccCheckVcdEdgeGatewayDhcpPool_basic = `
+resource "vcd_network" "test" {
+  name         = "terraform-acc-dhcp-pool"
+  edge_gateway = "%s"
+  gateway      = "10.10.103.1"
+
+  static_ip_pool {
+    start_address = "10.10.103.2"
+    end_address   = "10.10.103.50"
+  }
+}
+
+resource "vcd_edgegateway_dhcp_pool" "test" {
+  edge_gateway       = "%s"
+  network            = "${vcd_network.test.name}"
+  start_address      = "10.10.103.100"
+  end_address        = "10.10.103.150"
+  default_lease_time = %d
+}
+`
@@ -0,0 +1,277 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdEdgeGatewaySettings() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdEdgeGatewaySettingsCreate,
+		Update: resourceVcdEdgeGatewaySettingsUpdate,
+		Read:   resourceVcdEdgeGatewaySettingsRead,
+		Delete: resourceVcdEdgeGatewaySettingsDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"syslog_servers": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				MaxItems: 2,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: ValidateIPv4(),
+				},
+			},
+
+			"use_default_route_for_dns_relay": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+
+			// Name of the external network used for the default route
+			"default_route_network": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"interface": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"network": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"apply_rate_limit": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+
+						// Gbps
+						"incoming_rate_limit": &schema.Schema{
+							Type:     schema.TypeFloat,
+							Optional: true,
+						},
+
+						// Gbps
+						"outgoing_rate_limit": &schema.Schema{
+							Type:     schema.TypeFloat,
+							Optional: true,
+						},
+					},
+				},
+			},
+
+			// Any change redeploys the edge gateway
+			"redeploy_trigger": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// Any change syncs the syslog settings to the edge gateway
+			"sync_syslog_trigger": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func resourceVcdEdgeGatewaySettingsCreate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	if err != nil {
+		return fmt.Errorf("Unable to find edge gateway: %#v", err)
+	}
+
+	d.SetId(edgeGateway.EdgeGateway.HREF)
+
+	err = updateEdgeGatewayConfiguration(d.Id(), func(edgeGateway *EdgeGateway) error {
+		return expandEdgeGatewaySettings(d, edgeGateway)
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating edge gateway settings: %#v", err)
+	}
+
+	if _, ok := d.GetOk("syslog_servers"); ok {
+		err = runEdgeGatewayAction(d.Id(), "syncSyslogServerSettings", meta)
+		if err != nil {
+			return fmt.Errorf("Error syncing syslog settings: %#v", err)
+		}
+	}
+
+	return resourceVcdEdgeGatewaySettingsRead(d, meta)
+}
+
+func resourceVcdEdgeGatewaySettingsUpdate(d *schema.ResourceData, meta interface{}) error {
+	if d.HasChange("syslog_servers") || d.HasChange("use_default_route_for_dns_relay") ||
+		d.HasChange("default_route_network") || d.HasChange("interface") {
+		err := updateEdgeGatewayConfiguration(d.Id(), func(edgeGateway *EdgeGateway) error {
+			return expandEdgeGatewaySettings(d, edgeGateway)
+		}, meta)
+		if err != nil {
+			return fmt.Errorf("Error updating edge gateway settings: %#v", err)
+		}
+	}
+
+	if d.HasChange("syslog_servers") || d.HasChange("sync_syslog_trigger") {
+		err := runEdgeGatewayAction(d.Id(), "syncSyslogServerSettings", meta)
+		if err != nil {
+			return fmt.Errorf("Error syncing syslog settings: %#v", err)
+		}
+	}
+
+	if d.HasChange("redeploy_trigger") {
+		err := runEdgeGatewayAction(d.Id(), "redeploy", meta)
+		if err != nil {
+			return fmt.Errorf("Error redeploying edge gateway: %#v", err)
+		}
+	}
+
+	return resourceVcdEdgeGatewaySettingsRead(d, meta)
+}
+
+func resourceVcdEdgeGatewaySettingsRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	edgeGateway := &EdgeGateway{}
+	err := getEntity(d.Id(), edgeGateway, &vcdClient.Client)
+	if err != nil {
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Unable to find edge gateway. Removing from tfstate")
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading edge gateway: %#v", err)
+	}
+
+	if edgeGateway.Configuration == nil {
+		return nil
+	}
+
+	syslogServers := []interface{}{}
+	if settings := edgeGateway.Configuration.SyslogServerSettings; settings != nil {
+		for _, ip := range []string{settings.SyslogServerIp1, settings.SyslogServerIp2} {
+			if ip != "" {
+				syslogServers = append(syslogServers, ip)
+			}
+		}
+	}
+	d.Set("syslog_servers", syslogServers)
+	d.Set("use_default_route_for_dns_relay", edgeGateway.Configuration.UseDefaultRouteForDNSRelay)
+
+	var interfaces []*types.GatewayInterface
+	if edgeGateway.Configuration.GatewayInterfaces != nil {
+		interfaces = edgeGateway.Configuration.GatewayInterfaces.GatewayInterface
+	}
+
+	defaultRouteNetwork := ""
+	for _, gatewayInterface := range interfaces {
+		if gatewayInterface.UseForDefaultRoute && gatewayInterface.Network != nil {
+			defaultRouteNetwork = gatewayInterface.Network.Name
+		}
+	}
+	d.Set("default_route_network", defaultRouteNetwork)
+
+	// Only the interfaces that are configured are managed
+	configured := d.Get("interface").([]interface{})
+	interfaceList := make([]interface{}, 0, len(configured))
+	for _, raw := range configured {
+		name := raw.(map[string]interface{})["network"].(string)
+		gatewayInterface := findGatewayInterface(interfaces, name)
+		if gatewayInterface == nil {
+			log.Printf("[DEBUG] Edge gateway has no interface on network %s", name)
+			continue
+		}
+
+		interfaceList = append(interfaceList, map[string]interface{}{
+			"network":             name,
+			"apply_rate_limit":    gatewayInterface.ApplyRateLimit,
+			"incoming_rate_limit": gatewayInterface.InRateLimit,
+			"outgoing_rate_limit": gatewayInterface.OutRateLimit,
+		})
+	}
+	d.Set("interface", interfaceList)
+
+	return nil
+}
+
+// resourceVcdEdgeGatewaySettingsDelete only forgets the settings, the edge
+// gateway keeps them.
+func resourceVcdEdgeGatewaySettingsDelete(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+// expandEdgeGatewaySettings applies the configured settings to edgeGateway.
+// Settings that aren't configured are left alone.
+func expandEdgeGatewaySettings(d *schema.ResourceData, edgeGateway *EdgeGateway) error {
+	configuration := edgeGateway.Configuration
+	if configuration == nil || configuration.GatewayInterfaces == nil {
+		return fmt.Errorf("Edge gateway %s has no configuration", edgeGateway.Name)
+	}
+
+	if v, ok := d.GetOk("syslog_servers"); ok {
+		servers := v.([]interface{})
+		settings := &types.SyslogServerSettings{}
+		if len(servers) > 0 {
+			settings.SyslogServerIp1 = servers[0].(string)
+		}
+		if len(servers) > 1 {
+			settings.SyslogServerIp2 = servers[1].(string)
+		}
+		configuration.SyslogServerSettings = settings
+	}
+
+	if v, ok := d.GetOkExists("use_default_route_for_dns_relay"); ok {
+		configuration.UseDefaultRouteForDNSRelay = v.(bool)
+	}
+
+	if v, ok := d.GetOk("default_route_network"); ok {
+		if findGatewayInterface(configuration.GatewayInterfaces.GatewayInterface, v.(string)) == nil {
+			return fmt.Errorf("Edge gateway %s has no interface on network %s", edgeGateway.Name, v.(string))
+		}
+		for _, gatewayInterface := range configuration.GatewayInterfaces.GatewayInterface {
+			gatewayInterface.UseForDefaultRoute = gatewayInterface.Network != nil && gatewayInterface.Network.Name == v.(string)
+		}
+	}
+
+	for _, raw := range d.Get("interface").([]interface{}) {
+		data := raw.(map[string]interface{})
+		gatewayInterface := findGatewayInterface(configuration.GatewayInterfaces.GatewayInterface, data["network"].(string))
+		if gatewayInterface == nil {
+			return fmt.Errorf("Edge gateway %s has no interface on network %s", edgeGateway.Name, data["network"].(string))
+		}
+
+		gatewayInterface.ApplyRateLimit = data["apply_rate_limit"].(bool)
+		gatewayInterface.InRateLimit = data["incoming_rate_limit"].(float64)
+		gatewayInterface.OutRateLimit = data["outgoing_rate_limit"].(float64)
+	}
+
+	return nil
+}
+
+func findGatewayInterface(interfaces []*types.GatewayInterface, networkName string) *types.GatewayInterface {
+	for _, gatewayInterface := range interfaces {
+		if gatewayInterface.Network != nil && strings.EqualFold(gatewayInterface.Network.Name, networkName) {
+			return gatewayInterface
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func testAccCheckVcdEdgeGatewaySyslogServer(n, address string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		conn := testAccProvider.Meta().(*VCDClient)
+
+		edgeGateway := &EdgeGateway{}
+		err := getEntity(rs.Primary.ID, edgeGateway, &conn.Client)
+		if err != nil {
+			return err
+		}
+
+		if edgeGateway.Configuration == nil || edgeGateway.Configuration.SyslogServerSettings == nil ||
+			edgeGateway.Configuration.SyslogServerSettings.SyslogServerIp1 != address {
+			return fmt.Errorf("Edge gateway %s doesn't log to syslog server %s", edgeGateway.Name, address)
+		}
+
+		return nil
+	}
+}
+
+func TestAccVcdEdgeGatewaySettings_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewaySettings_basic, edgeGateway, "10.10.0.5"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVcdEdgeGatewaySyslogServer("vcd_edgegateway_settings.test", "10.10.0.5"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_settings.test", "syslog_servers.#", "1"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_settings.test", "syslog_servers.0", "10.10.0.5"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewaySettings_basic, edgeGateway, "10.10.0.6"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVcdEdgeGatewaySyslogServer("vcd_edgegateway_settings.test", "10.10.0.6"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_settings.test", "syslog_servers.0", "10.10.0.6"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdEdgeGatewaySettings_basic = `
+resource "vcd_edgegateway_settings" "test" {
+  edge_gateway   = "%s"
+  syslog_servers = ["%s"]
+}
+`
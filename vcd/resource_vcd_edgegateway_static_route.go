@@ -0,0 +1,224 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdEdgeGatewayStaticRoute() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdEdgeGatewayStaticRouteCreate,
+		Update: resourceVcdEdgeGatewayStaticRouteUpdate,
+		Read:   resourceVcdEdgeGatewayStaticRouteRead,
+		Delete: resourceVcdEdgeGatewayStaticRouteDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"network": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"next_hop_ip": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: ValidateIPv4(),
+			},
+
+			"interface": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"gateway_interface"},
+				ValidateFunc: validation.StringInSlice([]string{
+					"Internal",
+					"External",
+				}, false),
+			},
+
+			// Name of the network connected to the gateway interface
+			"gateway_interface": &schema.Schema{
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"interface"},
+			},
+		},
+	}
+}
+
+func resourceVcdEdgeGatewayStaticRouteCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		if findStaticRoute(services.StaticRoutingService, name) != nil {
+			return nil, fmt.Errorf("Edge gateway %s already has a static route named %s", edgeGateway.Name, name)
+		}
+
+		route, err := expandStaticRoute(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			StaticRoutingService: replaceStaticRoute(services.StaticRoutingService, name, route),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding static route %s: %#v", name, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", edgeGateway.HREF, name))
+
+	return resourceVcdEdgeGatewayStaticRouteRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayStaticRouteUpdate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		route, err := expandStaticRoute(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			StaticRoutingService: replaceStaticRoute(services.StaticRoutingService, name, route),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating static route %s: %#v", name, err)
+	}
+
+	return resourceVcdEdgeGatewayStaticRouteRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayStaticRouteRead(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	route := findStaticRoute(edgeGatewayServices(edgeGateway).StaticRoutingService, d.Get("name").(string))
+	if route == nil {
+		log.Printf("[DEBUG] Unable to find static route %s. Removing from tfstate", d.Get("name").(string))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("network", route.Network)
+	d.Set("next_hop_ip", route.NextHopIP)
+	d.Set("interface", route.Interface)
+	if route.GatewayInterface != nil {
+		d.Set("gateway_interface", route.GatewayInterface.Name)
+	} else {
+		d.Set("gateway_interface", "")
+	}
+
+	return nil
+}
+
+func resourceVcdEdgeGatewayStaticRouteDelete(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			StaticRoutingService: replaceStaticRoute(services.StaticRoutingService, name, nil),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing static route %s: %#v", name, err)
+	}
+
+	return nil
+}
+
+func expandStaticRoute(d *schema.ResourceData, edgeGateway *EdgeGateway) (*types.StaticRoute, error) {
+	route := &types.StaticRoute{
+		Name:      d.Get("name").(string),
+		Network:   d.Get("network").(string),
+		NextHopIP: d.Get("next_hop_ip").(string),
+		Interface: d.Get("interface").(string),
+	}
+
+	if gatewayInterface := d.Get("gateway_interface").(string); gatewayInterface != "" {
+		network, err := findGatewayInterfaceNetwork(edgeGateway, gatewayInterface)
+		if err != nil {
+			return nil, err
+		}
+		route.GatewayInterface = network
+	}
+
+	return route, nil
+}
+
+// findGatewayInterfaceNetwork returns the network of the edge gateway
+// interface connected to the network named name.
+func findGatewayInterfaceNetwork(edgeGateway *EdgeGateway, name string) (*types.Reference, error) {
+	if edgeGateway.Configuration != nil && edgeGateway.Configuration.GatewayInterfaces != nil {
+		for _, gatewayInterface := range edgeGateway.Configuration.GatewayInterfaces.GatewayInterface {
+			if gatewayInterface.Network != nil && gatewayInterface.Network.Name == name {
+				return gatewayInterface.Network, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("Edge gateway %s has no interface on network %s", edgeGateway.Name, name)
+}
+
+func findStaticRoute(service *StaticRoutingService, name string) *types.StaticRoute {
+	if service == nil {
+		return nil
+	}
+
+	for _, route := range service.StaticRoute {
+		if route.Name == name {
+			return route
+		}
+	}
+
+	return nil
+}
+
+// replaceStaticRoute returns the static routing service with the route named
+// name replaced by route, or removed when route is nil. The service is
+// enabled whenever it has routes.
+func replaceStaticRoute(service *StaticRoutingService, name string, route *types.StaticRoute) *StaticRoutingService {
+	newService := &StaticRoutingService{}
+
+	found := false
+	if service != nil {
+		for _, existing := range service.StaticRoute {
+			if existing.Name != name {
+				newService.StaticRoute = append(newService.StaticRoute, existing)
+				continue
+			}
+			found = true
+			if route != nil {
+				newService.StaticRoute = append(newService.StaticRoute, route)
+			}
+		}
+	}
+
+	if !found && route != nil {
+		newService.StaticRoute = append(newService.StaticRoute, route)
+	}
+
+	newService.IsEnabled = len(newService.StaticRoute) > 0 || (service != nil && service.IsEnabled)
+
+	return newService
+}
@@ -0,0 +1,105 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func TestReplaceStaticRoute(t *testing.T) {
+	a := &types.StaticRoute{Name: "a", Network: "10.1.0.0/24"}
+	b := &types.StaticRoute{Name: "b", Network: "10.2.0.0/24"}
+	newB := &types.StaticRoute{Name: "b", Network: "10.3.0.0/24"}
+
+	cases := []struct {
+		name     string
+		service  *StaticRoutingService
+		route    string
+		new      *types.StaticRoute
+		expected *StaticRoutingService
+	}{
+		{"add to missing service", nil, "a", a,
+			&StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{a}}},
+		{"add", &StaticRoutingService{StaticRoute: []*types.StaticRoute{a}}, "b", b,
+			&StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{a, b}}},
+		{"replace in place", &StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{b, a}}, "b", newB,
+			&StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{newB, a}}},
+		{"remove", &StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{a, b}}, "a", nil,
+			&StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{b}}},
+		{"remove last keeps the service state", &StaticRoutingService{StaticRoute: []*types.StaticRoute{a}}, "a", nil,
+			&StaticRoutingService{}},
+		{"remove missing", &StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{a}}, "b", nil,
+			&StaticRoutingService{IsEnabled: true, StaticRoute: []*types.StaticRoute{a}}},
+	}
+
+	for _, c := range cases {
+		actual := replaceStaticRoute(c.service, c.route, c.new)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func testAccCheckVcdEdgeGatewayStaticRouteDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_edgegateway_static_route" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if findStaticRoute(edgeGatewayServices(edgeGateway).StaticRoutingService, rs.Primary.Attributes["name"]) != nil {
+			return fmt.Errorf("Static route %s still exists", rs.Primary.Attributes["name"])
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdEdgeGatewayStaticRoute_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdEdgeGatewayStaticRouteDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayStaticRoute_basic, edgeGateway, "10.10.200.1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_static_route.test", "network", "10.10.100.0/24"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_static_route.test", "next_hop_ip", "10.10.200.1"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_static_route.test", "interface", "External"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayStaticRoute_basic, edgeGateway, "10.10.200.2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_static_route.test", "next_hop_ip", "10.10.200.2"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdEdgeGatewayStaticRoute_basic = `
+resource "vcd_edgegateway_static_route" "test" {
+  edge_gateway = "%s"
+  name         = "terraform-acc-route"
+  network      = "10.10.100.0/24"
+  next_hop_ip  = "%s"
+  interface    = "External"
+}
+`
@@ -0,0 +1,124 @@
+package vcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestGatewayConfigurationDisabled(t *testing.T) {
+	output, err := xml.Marshal(&GatewayConfiguration{GatewayBackingConfig: "compact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, element := range []string{"<HaEnabled>false</HaEnabled>", "<UseDefaultRouteForDnsRelay>false</UseDefaultRouteForDnsRelay>"} {
+		if !strings.Contains(string(output), element) {
+			t.Errorf("Expected %s in %s", element, output)
+		}
+	}
+}
+
+func testAccPreCheckExternalNetwork(t *testing.T) {
+	testAccPreCheck(t)
+
+	for _, name := range []string{"VCD_EXTERNAL_NETWORK", "VCD_EXTERNAL_NETWORK_GATEWAY", "VCD_EXTERNAL_NETWORK_NETMASK"} {
+		if v := os.Getenv(name); v == "" {
+			t.Skipf("%s must be set for edge gateway acceptance tests", name)
+		}
+	}
+}
+
+func testAccCheckVcdEdgeGatewayExists(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No edge gateway ID is set")
+		}
+
+		conn := testAccProvider.Meta().(*VCDClient)
+
+		return getEntity(rs.Primary.ID, &EdgeGateway{}, &conn.Client)
+	}
+}
+
+func testAccCheckVcdEdgeGatewayDestroy(s *terraform.State) error {
+	conn := testAccProvider.Meta().(*VCDClient)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_edgegateway" {
+			continue
+		}
+
+		err := getEntity(rs.Primary.ID, &EdgeGateway{}, &conn.Client)
+		if err == nil {
+			return fmt.Errorf("Edge gateway %s still exists", rs.Primary.ID)
+		}
+		if !isNotFoundError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdEdgeGateway_Basic(t *testing.T) {
+	externalNetwork := os.Getenv("VCD_EXTERNAL_NETWORK")
+	gateway := os.Getenv("VCD_EXTERNAL_NETWORK_GATEWAY")
+	netmask := os.Getenv("VCD_EXTERNAL_NETWORK_NETMASK")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckExternalNetwork(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdEdgeGatewayDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGateway_basic, "Created by terraform", externalNetwork, gateway, netmask),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVcdEdgeGatewayExists("vcd_edgegateway.test"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway.test", "name", "terraform-acc-edge-gateway"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway.test", "description", "Created by terraform"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway.test", "backing_config", "compact"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway.test", "external_network.#", "1"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway.test", "external_network.0.name", externalNetwork),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGateway_basic, "Updated by terraform", externalNetwork, gateway, netmask),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVcdEdgeGatewayExists("vcd_edgegateway.test"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway.test", "description", "Updated by terraform"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdEdgeGateway_basic = `
+resource "vcd_edgegateway" "test" {
+  name        = "terraform-acc-edge-gateway"
+  description = "%s"
+
+  external_network {
+    name                  = "%s"
+    gateway               = "%s"
+    netmask               = "%s"
+    use_for_default_route = true
+  }
+}
+`
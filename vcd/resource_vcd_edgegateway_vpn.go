@@ -2,18 +2,23 @@ package vcd
 
 import (
 	"fmt"
-	"github.com/hashicorp/terraform/helper/resource"
+	"log"
+
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	types "github.com/vCloud/govcloudair/types/v56"
-	"log"
 )
 
 func resourceVcdEdgeGatewayVpn() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVcdEdgeGatewayVpnCreate,
+		Update: resourceVcdEdgeGatewayVpnUpdate,
 		Read:   resourceVcdEdgeGatewayVpnRead,
 		Delete: resourceVcdEdgeGatewayVpnDelete,
 
+		SchemaVersion: 1,
+		MigrateState:  resourceVcdEdgeGatewayVpnMigrateState,
+
 		Schema: map[string]*schema.Schema{
 
 			"edge_gateway": &schema.Schema{
@@ -31,55 +36,89 @@ func resourceVcdEdgeGatewayVpn() *schema.Resource {
 			"description": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
 			},
 
 			"encryption_protocol": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"local_ip_address": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"local_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"mtu": &schema.Schema{
 				Type:     schema.TypeInt,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"peer_ip_address": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"peer_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
+			},
+
+			// third_party for peers outside of vCD, local for a network of
+			// this vCD set in local_peer_id and local_peer_name
+			"peer_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "third_party",
+				ValidateFunc: validation.StringInSlice([]string{
+					"third_party",
+					"local",
+				}, false),
+			},
+
+			"local_peer_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"local_peer_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
 			},
 
 			"shared_secret": &schema.Schema{
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+
+			// Name of the external network of the VPN service endpoint,
+			// shared by all the tunnels of the edge gateway
+			"endpoint_network": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Optional: true,
+				Computed: true,
+			},
+
+			"endpoint_public_ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
 			},
 
 			"local_subnets": &schema.Schema{
 				Type:     schema.TypeSet,
 				Optional: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"local_subnet_name": &schema.Schema{
@@ -103,7 +142,6 @@ func resourceVcdEdgeGatewayVpn() *schema.Resource {
 			"peer_subnets": &schema.Schema{
 				Type:     schema.TypeSet,
 				Optional: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"peer_subnet_name": &schema.Schema{
@@ -128,13 +166,132 @@ func resourceVcdEdgeGatewayVpn() *schema.Resource {
 }
 
 func resourceVcdEdgeGatewayVpnCreate(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	log.Printf("[TRACE] CLIENT: %#v", vcdClient)
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
+	name := d.Get("name").(string)
+
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		if findVpnTunnel(services.GatewayIpsecVpnService, name) != nil {
+			return nil, fmt.Errorf("Edge gateway %s already has a VPN tunnel named %s", edgeGateway.Name, name)
+		}
+
+		return expandVpnService(d, edgeGateway, services)
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding VPN tunnel %s: %#v", name, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", edgeGateway.HREF, name))
+
+	return resourceVcdEdgeGatewayVpnRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayVpnUpdate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return expandVpnService(d, edgeGateway, services)
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating VPN tunnel %s: %#v", name, err)
+	}
+
+	return resourceVcdEdgeGatewayVpnRead(d, meta)
+}
+
+func resourceVcdEdgeGatewayVpnDelete(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			GatewayIpsecVpnService: replaceVpnTunnel(services.GatewayIpsecVpnService, name, nil),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing VPN tunnel %s: %#v", name, err)
+	}
+
+	return nil
+}
 
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+func resourceVcdEdgeGatewayVpnRead(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	service := edgeGatewayServices(edgeGateway).GatewayIpsecVpnService
+	tunnel := findVpnTunnel(service, d.Get("name").(string))
+	if tunnel == nil {
+		log.Printf("[DEBUG] Unable to find VPN tunnel %s. Removing from tfstate", d.Get("name").(string))
+		d.SetId("")
+		return nil
+	}
 
+	d.Set("description", tunnel.Description)
+	d.Set("enabled", tunnel.IsEnabled)
+	d.Set("encryption_protocol", tunnel.EncryptionProtocol)
+	d.Set("local_ip_address", tunnel.LocalIPAddress)
+	d.Set("local_id", tunnel.LocalID)
+	d.Set("mtu", tunnel.Mtu)
+	d.Set("peer_ip_address", tunnel.PeerIPAddress)
+	d.Set("peer_id", tunnel.PeerID)
+	// vCD may only return the encrypted secret, which can't be compared
+	if !tunnel.SharedSecretEncrypted {
+		d.Set("shared_secret", tunnel.SharedSecret)
+	}
+
+	if tunnel.IpsecVpnLocalPeer != nil && tunnel.IpsecVpnLocalPeer.ID != "" {
+		d.Set("peer_type", "local")
+		d.Set("local_peer_id", tunnel.IpsecVpnLocalPeer.ID)
+		d.Set("local_peer_name", tunnel.IpsecVpnLocalPeer.Name)
+	} else {
+		d.Set("peer_type", "third_party")
+		d.Set("local_peer_id", "")
+		d.Set("local_peer_name", "")
+	}
+
+	if err := d.Set("local_subnets", flattenVpnSubnets(tunnel.LocalSubnet, "local")); err != nil {
+		return fmt.Errorf("Error setting local_subnets: %#v", err)
+	}
+	if err := d.Set("peer_subnets", flattenVpnSubnets(tunnel.PeerSubnet, "peer")); err != nil {
+		return fmt.Errorf("Error setting peer_subnets: %#v", err)
+	}
+
+	if service.Endpoint != nil {
+		if service.Endpoint.Network != nil {
+			d.Set("endpoint_network", service.Endpoint.Network.Name)
+		}
+		d.Set("endpoint_public_ip", service.Endpoint.PublicIP)
+	}
+
+	return nil
+}
+
+// expandVpnService returns the IPsec VPN service of the edge gateway with the
+// tunnel of d added or replaced, and the endpoint set if configured.
+func expandVpnService(d *schema.ResourceData, edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+	service := replaceVpnTunnel(services.GatewayIpsecVpnService, d.Get("name").(string), expandVpnTunnel(d))
+
+	if endpointNetwork := d.Get("endpoint_network").(string); endpointNetwork != "" {
+		network, err := findGatewayInterfaceNetwork(edgeGateway, endpointNetwork)
+		if err != nil {
+			return nil, err
+		}
+
+		service.Endpoint = &types.GatewayIpsecVpnEndpoint{
+			Network: &types.Reference{
+				HREF: network.HREF,
+				Name: network.Name,
+			},
+			PublicIP: d.Get("endpoint_public_ip").(string),
+		}
+	}
+
+	return &EdgeGatewayServiceConfiguration{
+		GatewayIpsecVpnService: service,
+	}, nil
+}
+
+func expandVpnTunnel(d *schema.ResourceData) *GatewayIpsecVpnTunnel {
 	localSubnetsList := d.Get("local_subnets").(*schema.Set).List()
 	peerSubnetsList := d.Get("peer_subnets").(*schema.Set).List()
 
@@ -159,13 +316,9 @@ func resourceVcdEdgeGatewayVpnCreate(d *schema.ResourceData, meta interface{}) e
 		}
 	}
 
-	tunnel := &types.GatewayIpsecVpnTunnel{
-		Name:        d.Get("name").(string),
-		Description: d.Get("description").(string),
-		IpsecVpnLocalPeer: &types.IpsecVpnLocalPeer{
-			ID:   "",
-			Name: "",
-		},
+	tunnel := &GatewayIpsecVpnTunnel{
+		Name:               d.Get("name").(string),
+		Description:        d.Get("description").(string),
 		EncryptionProtocol: d.Get("encryption_protocol").(string),
 		LocalIPAddress:     d.Get("local_ip_address").(string),
 		LocalID:            d.Get("local_id").(string),
@@ -175,116 +328,80 @@ func resourceVcdEdgeGatewayVpnCreate(d *schema.ResourceData, meta interface{}) e
 		PeerIPAddress:      d.Get("peer_ip_address").(string),
 		PeerSubnet:         peerSubnets,
 		SharedSecret:       d.Get("shared_secret").(string),
-		IsEnabled:          true,
+		IsEnabled:          d.Get("enabled").(bool),
 	}
 
-	tunnels := make([]*types.GatewayIpsecVpnTunnel, 1)
-	tunnels[0] = tunnel
-
-	ipsecVPNConfig := &types.EdgeGatewayServiceConfiguration{
-		Xmlns: "http://www.vmware.com/vcloud/v1.5",
-		GatewayIpsecVpnService: &types.GatewayIpsecVpnService{
-			IsEnabled: true,
-			Tunnel:    tunnels,
-		},
-	}
-
-	log.Printf("[INFO] ipsecVPNConfig: %#v", ipsecVPNConfig)
-
-	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		edgeGateway.Refresh()
-		task, err := edgeGateway.AddIpsecVPN(ipsecVPNConfig)
-		if err != nil {
-			log.Printf("[INFO] Error setting ipsecVPNConfig rules: %s", err)
-			return resource.RetryableError(
-				fmt.Errorf("Error setting ipsecVPNConfig rules: %#v", err))
+	if d.Get("peer_type").(string) == "local" {
+		tunnel.IpsecVpnLocalPeer = &types.IpsecVpnLocalPeer{
+			ID:   d.Get("local_peer_id").(string),
+			Name: d.Get("local_peer_name").(string),
+		}
+	} else {
+		tunnel.IpsecVpnThirdPartyPeer = &types.IpsecVpnThirdPartyPeer{
+			PeerID: d.Get("peer_id").(string),
 		}
-
-		return resource.RetryableError(task.WaitTaskCompletion())
-	})
-	if err != nil {
-		return fmt.Errorf("Error completing tasks: %#v", err)
 	}
 
-	d.SetId(d.Get("edge_gateway").(string))
-
-	return resourceVcdEdgeGatewayVpnRead(d, meta)
+	return tunnel
 }
 
-func resourceVcdEdgeGatewayVpnDelete(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-
-	log.Printf("[TRACE] CLIENT: %#v", vcdClient)
-
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
-
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
-
-	ipsecVPNConfig := &types.EdgeGatewayServiceConfiguration{
-		Xmlns: "http://www.vmware.com/vcloud/v1.5",
-		GatewayIpsecVpnService: &types.GatewayIpsecVpnService{
-			IsEnabled: false,
-		},
+// flattenVpnSubnets converts subnets into the local_subnets or peer_subnets
+// set, as selected by prefix.
+func flattenVpnSubnets(subnets []*types.IpsecVpnSubnet, prefix string) []interface{} {
+	result := make([]interface{}, 0, len(subnets))
+	for _, subnet := range subnets {
+		result = append(result, map[string]interface{}{
+			prefix + "_subnet_name":    subnet.Name,
+			prefix + "_subnet_gateway": subnet.Gateway,
+			prefix + "_subnet_mask":    subnet.Netmask,
+		})
 	}
+	return result
+}
 
-	log.Printf("[INFO] ipsecVPNConfig: %#v", ipsecVPNConfig)
-
-	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		edgeGateway.Refresh()
-		task, err := edgeGateway.AddIpsecVPN(ipsecVPNConfig)
-		if err != nil {
-			log.Printf("[INFO] Error setting ipsecVPNConfig rules: %s", err)
-			return resource.RetryableError(
-				fmt.Errorf("Error setting ipsecVPNConfig rules: %#v", err))
-		}
-
-		return resource.RetryableError(task.WaitTaskCompletion())
-	})
-	if err != nil {
-		return fmt.Errorf("Error completing tasks: %#v", err)
+func findVpnTunnel(service *GatewayIpsecVpnService, name string) *GatewayIpsecVpnTunnel {
+	if service == nil {
+		return nil
 	}
 
-	d.SetId(d.Get("edge_gateway").(string))
-
-	if err != nil {
-		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	for _, tunnel := range service.Tunnel {
+		if tunnel.Name == name {
+			return tunnel
+		}
 	}
 
 	return nil
 }
 
-func resourceVcdEdgeGatewayVpnRead(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
-	if err != nil {
-		return fmt.Errorf("Error finding edge gateway: %#v", err)
+// replaceVpnTunnel returns a copy of the IPsec VPN service with the tunnel
+// named name replaced by tunnel, or removed when tunnel is nil. The service
+// is enabled whenever it has tunnels.
+func replaceVpnTunnel(service *GatewayIpsecVpnService, name string, tunnel *GatewayIpsecVpnTunnel) *GatewayIpsecVpnService {
+	newService := &GatewayIpsecVpnService{}
+	if service != nil {
+		*newService = *service
 	}
-
-	egsc := edgeGateway.EdgeGateway.Configuration.EdgeGatewayServiceConfiguration.GatewayIpsecVpnService
-
-	if len(egsc.Tunnel) == 0 {
-		d.SetId("")
-		return nil
+	newService.Tunnel = nil
+
+	found := false
+	if service != nil {
+		for _, existing := range service.Tunnel {
+			if existing.Name != name {
+				newService.Tunnel = append(newService.Tunnel, existing)
+				continue
+			}
+			found = true
+			if tunnel != nil {
+				newService.Tunnel = append(newService.Tunnel, tunnel)
+			}
+		}
 	}
 
-	if len(egsc.Tunnel) == 1 {
-		tunnel := egsc.Tunnel[0]
-		d.Set("name", tunnel.Name)
-		d.Set("description", tunnel.Description)
-		d.Set("encryption_protocol", tunnel.EncryptionProtocol)
-		d.Set("local_ip_address", tunnel.LocalIPAddress)
-		d.Set("local_id", tunnel.LocalID)
-		d.Set("mtu", tunnel.Mtu)
-		d.Set("peer_ip_address", tunnel.PeerIPAddress)
-		d.Set("peer_id", tunnel.PeerID)
-		d.Set("shared_secret", tunnel.SharedSecret)
-		d.Set("local_subnets", tunnel.LocalSubnet)
-		d.Set("peer_subnets", tunnel.PeerSubnet)
-	} else {
-		return fmt.Errorf("Multiple tunnels not currently supported")
+	if !found && tunnel != nil {
+		newService.Tunnel = append(newService.Tunnel, tunnel)
 	}
 
-	return nil
+	newService.IsEnabled = len(newService.Tunnel) > 0 || newService.IsEnabled
+
+	return newService
 }
@@ -0,0 +1,39 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func resourceVcdEdgeGatewayVpnMigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found vcd_edgegateway_vpn State v0; migrating to v1")
+		return migrateVcdEdgeGatewayVpnStateV0toV1(is, meta)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+// migrateVcdEdgeGatewayVpnStateV0toV1 replaces the edge gateway name, which
+// was used as the ID, with the edge gateway HREF and the tunnel name.
+func migrateVcdEdgeGatewayVpnStateV0toV1(is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	vcdClient := meta.(*VCDClient)
+	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(is.Attributes["edge_gateway"])
+	if err != nil {
+		return is, fmt.Errorf("Error finding edge gateway %s: %#v", is.Attributes["edge_gateway"], err)
+	}
+	is.ID = fmt.Sprintf("%s:%s", edgeGateway.EdgeGateway.HREF, is.Attributes["name"])
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
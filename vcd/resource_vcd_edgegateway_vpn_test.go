@@ -0,0 +1,174 @@
+package vcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func TestReplaceVpnTunnel(t *testing.T) {
+	a := &GatewayIpsecVpnTunnel{Name: "a", Mtu: 1500}
+	b := &GatewayIpsecVpnTunnel{Name: "b", Mtu: 1500}
+	newB := &GatewayIpsecVpnTunnel{Name: "b", Mtu: 1400}
+	endpoint := &types.GatewayIpsecVpnEndpoint{PublicIP: "10.10.0.1"}
+
+	cases := []struct {
+		name     string
+		service  *GatewayIpsecVpnService
+		tunnel   string
+		new      *GatewayIpsecVpnTunnel
+		expected *GatewayIpsecVpnService
+	}{
+		{"add to missing service", nil, "a", a,
+			&GatewayIpsecVpnService{IsEnabled: true, Tunnel: []*GatewayIpsecVpnTunnel{a}}},
+		{"add keeps the endpoint", &GatewayIpsecVpnService{Endpoint: endpoint, Tunnel: []*GatewayIpsecVpnTunnel{a}}, "b", b,
+			&GatewayIpsecVpnService{IsEnabled: true, Endpoint: endpoint, Tunnel: []*GatewayIpsecVpnTunnel{a, b}}},
+		{"replace in place", &GatewayIpsecVpnService{IsEnabled: true, Tunnel: []*GatewayIpsecVpnTunnel{b, a}}, "b", newB,
+			&GatewayIpsecVpnService{IsEnabled: true, Tunnel: []*GatewayIpsecVpnTunnel{newB, a}}},
+		{"remove", &GatewayIpsecVpnService{IsEnabled: true, Tunnel: []*GatewayIpsecVpnTunnel{a, b}}, "a", nil,
+			&GatewayIpsecVpnService{IsEnabled: true, Tunnel: []*GatewayIpsecVpnTunnel{b}}},
+		{"remove last keeps the service state", &GatewayIpsecVpnService{Tunnel: []*GatewayIpsecVpnTunnel{a}}, "a", nil,
+			&GatewayIpsecVpnService{}},
+		{"remove missing", &GatewayIpsecVpnService{IsEnabled: true, Tunnel: []*GatewayIpsecVpnTunnel{a}}, "b", nil,
+			&GatewayIpsecVpnService{IsEnabled: true, Tunnel: []*GatewayIpsecVpnTunnel{a}}},
+	}
+
+	for _, c := range cases {
+		actual := replaceVpnTunnel(c.service, c.tunnel, c.new)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestExpandVpnTunnelPeer(t *testing.T) {
+	cases := []struct {
+		peerType       string
+		localPeer      *types.IpsecVpnLocalPeer
+		thirdPartyPeer *types.IpsecVpnThirdPartyPeer
+	}{
+		{"third_party", nil, &types.IpsecVpnThirdPartyPeer{PeerID: "peer"}},
+		{"local", &types.IpsecVpnLocalPeer{ID: "local-id", Name: "local-name"}, nil},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceVcdEdgeGatewayVpn().Schema, map[string]interface{}{
+			"name":            "tunnel",
+			"peer_id":         "peer",
+			"peer_type":       c.peerType,
+			"local_peer_id":   "local-id",
+			"local_peer_name": "local-name",
+		})
+
+		tunnel := expandVpnTunnel(d)
+		if !reflect.DeepEqual(tunnel.IpsecVpnLocalPeer, c.localPeer) {
+			t.Errorf("%s: expected local peer %#v, got %#v", c.peerType, c.localPeer, tunnel.IpsecVpnLocalPeer)
+		}
+		if !reflect.DeepEqual(tunnel.IpsecVpnThirdPartyPeer, c.thirdPartyPeer) {
+			t.Errorf("%s: expected third party peer %#v, got %#v", c.peerType, c.thirdPartyPeer, tunnel.IpsecVpnThirdPartyPeer)
+		}
+	}
+}
+
+func TestVpnTunnelDisabled(t *testing.T) {
+	output, err := xml.Marshal(&GatewayIpsecVpnTunnel{Name: "tunnel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(output), "<IsEnabled>false</IsEnabled>") {
+		t.Errorf("Expected a disabled tunnel in %s", output)
+	}
+}
+
+func testAccCheckVcdEdgeGatewayVpnDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_edgegateway_vpn" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if findVpnTunnel(edgeGatewayServices(edgeGateway).GatewayIpsecVpnService, rs.Primary.Attributes["name"]) != nil {
+			return fmt.Errorf("VPN tunnel %s still exists", rs.Primary.Attributes["name"])
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdEdgeGatewayVpn_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+	externalIP := os.Getenv("VCD_EXTERNAL_IP")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if externalIP == "" {
+				t.Skip("VCD_EXTERNAL_IP must be set for VPN acceptance tests")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdEdgeGatewayVpnDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayVpn_basic, edgeGateway, externalIP, externalIP, 1400),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_vpn.test", "peer_type", "third_party"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_vpn.test", "mtu", "1400"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_vpn.test", "local_subnets.#", "1"),
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_vpn.test", "peer_subnets.#", "1"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdEdgeGatewayVpn_basic, edgeGateway, externalIP, externalIP, 1500),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_edgegateway_vpn.test", "mtu", "1500"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdEdgeGatewayVpn_basic = `
+resource "vcd_edgegateway_vpn" "test" {
+  edge_gateway        = "%s"
+  name                = "terraform-acc-vpn"
+  description         = "Description"
+  encryption_protocol = "AES256"
+  local_ip_address    = "%s"
+  local_id            = "%s"
+  mtu                 = %d
+  peer_ip_address     = "198.51.100.10"
+  peer_id             = "198.51.100.10"
+  shared_secret       = "terraform-acc-secret"
+
+  local_subnets {
+    local_subnet_name    = "local"
+    local_subnet_gateway = "10.10.102.1"
+    local_subnet_mask    = "255.255.255.0"
+  }
+
+  peer_subnets {
+    peer_subnet_name    = "peer"
+    peer_subnet_gateway = "10.20.0.1"
+    peer_subnet_mask    = "255.255.255.0"
+  }
+}
+`
@@ -0,0 +1,258 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceVcdFirewallRule() *schema.Resource {
+	s := FirewallRuleSubresourceSchema()
+	s["edge_gateway"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+		ForceNew: true,
+	}
+
+	// Id of the rule this rule is placed before
+	s["before"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"after", "position"},
+	}
+
+	// Id of the rule this rule is placed after
+	s["after"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"before", "position"},
+	}
+
+	// Position of the rule in the firewall rules, starting at 1
+	s["position"] = &schema.Schema{
+		Type:          schema.TypeInt,
+		Optional:      true,
+		ConflictsWith: []string{"before", "after"},
+		ValidateFunc:  validation.IntAtLeast(1),
+	}
+
+	s["rule_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		Create: resourceVcdFirewallRuleCreate,
+		Update: resourceVcdFirewallRuleUpdate,
+		Read:   resourceVcdFirewallRuleRead,
+		Delete: resourceVcdFirewallRuleDelete,
+
+		Schema: s,
+	}
+}
+
+func resourceVcdFirewallRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	rule := expandFirewallRule(d, "")
+
+	// sent ends up with the services as sent to vCD, once every change
+	// batched with this one has been applied to them
+	var sent *GatewayFeatures
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		sent = services
+
+		rules, err := placeFirewallRule(firewallRulesOf(services.FirewallService), "", rule, d)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			FirewallService: replaceFirewallRules(services.FirewallService, rules),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding firewall rule: %#v", err)
+	}
+
+	id, err := findFirewallRuleID(firewallRulesOf(edgeGatewayServices(edgeGateway).FirewallService), firewallRulesOf(sent.FirewallService), rule)
+	if err != nil {
+		return fmt.Errorf("Error finding the Id of the new firewall rule: %#v", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", edgeGateway.HREF, id))
+	d.Set("rule_id", id)
+
+	return resourceVcdFirewallRuleRead(d, meta)
+}
+
+func resourceVcdFirewallRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	id := d.Get("rule_id").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		rule := expandFirewallRule(d, "")
+		rule.ID = id
+
+		rules, err := placeFirewallRule(firewallRulesOf(services.FirewallService), id, rule, d)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			FirewallService: replaceFirewallRules(services.FirewallService, rules),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating firewall rule %s: %#v", id, err)
+	}
+
+	return resourceVcdFirewallRuleRead(d, meta)
+}
+
+func resourceVcdFirewallRuleRead(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	id := d.Get("rule_id").(string)
+	_, rule := findFirewallRule(firewallRulesOf(edgeGatewayServices(edgeGateway).FirewallService), id)
+	if rule == nil {
+		log.Printf("[DEBUG] Unable to find firewall rule %s. Removing from tfstate", id)
+		d.SetId("")
+		return nil
+	}
+
+	for key, value := range flattenFirewallRule(rule) {
+		if key == "id" {
+			continue
+		}
+		d.Set(key, value)
+	}
+
+	return nil
+}
+
+func resourceVcdFirewallRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	id := d.Get("rule_id").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		rules := firewallRulesOf(services.FirewallService)
+		if i, _ := findFirewallRule(rules, id); i >= 0 {
+			rules = append(rules[:i:i], rules[i+1:]...)
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			FirewallService: replaceFirewallRules(services.FirewallService, rules),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing firewall rule %s: %#v", id, err)
+	}
+
+	return nil
+}
+
+func firewallRulesOf(service *FirewallService) []*FirewallRule {
+	if service == nil {
+		return nil
+	}
+	return service.FirewallRule
+}
+
+// findFirewallRule returns the position and the rule with the given Id, or
+// -1 and nil.
+func findFirewallRule(rules []*FirewallRule, id string) (int, *FirewallRule) {
+	for i, rule := range rules {
+		if rule.ID == id {
+			return i, rule
+		}
+	}
+	return -1, nil
+}
+
+// findFirewallRuleID returns the Id vCD gave to rule. vCD assigns the Ids of
+// new firewall rules and keeps the rules in the order they were sent in, so
+// the Id is the one of the rule at the position of rule in sent. The Id must
+// not be one of the sent rules, which would mean the rules changed meanwhile.
+func findFirewallRuleID(rules, sent []*FirewallRule, rule *FirewallRule) (string, error) {
+	if len(rules) != len(sent) {
+		return "", fmt.Errorf("Sent %d firewall rules, found %d", len(sent), len(rules))
+	}
+
+	for i, candidate := range sent {
+		if candidate != rule {
+			continue
+		}
+
+		if j, _ := findFirewallRule(sent, rules[i].ID); rules[i].ID == "" || j >= 0 {
+			return "", fmt.Errorf("Firewall rule %d has no new Id", i+1)
+		}
+		return rules[i].ID, nil
+	}
+
+	return "", fmt.Errorf("Unable to find rule")
+}
+
+// placeFirewallRule returns rules with the rule with the given Id replaced by
+// rule. The rule is moved according to the before, after and position
+// arguments when they are set or changed, otherwise it stays in place. New
+// rules without positioning are added at the end.
+func placeFirewallRule(rules []*FirewallRule, id string, rule *FirewallRule, d *schema.ResourceData) ([]*FirewallRule, error) {
+	position := -1
+	if id != "" {
+		position, _ = findFirewallRule(rules, id)
+	}
+
+	result := make([]*FirewallRule, 0, len(rules)+1)
+	for i, existing := range rules {
+		if i != position {
+			result = append(result, existing)
+		}
+	}
+
+	if position < 0 || d.HasChange("before") || d.HasChange("after") || d.HasChange("position") {
+		if before := d.Get("before").(string); before != "" {
+			position, _ = findFirewallRule(result, before)
+			if position < 0 {
+				return nil, fmt.Errorf("Unable to find firewall rule %s to place the rule before", before)
+			}
+		} else if after := d.Get("after").(string); after != "" {
+			position, _ = findFirewallRule(result, after)
+			if position < 0 {
+				return nil, fmt.Errorf("Unable to find firewall rule %s to place the rule after", after)
+			}
+			position++
+		} else if index := d.Get("position").(int); index > 0 {
+			position = index - 1
+		} else if position < 0 {
+			position = len(result)
+		}
+	}
+
+	if position > len(result) {
+		position = len(result)
+	}
+
+	result = append(result, nil)
+	copy(result[position+1:], result[position:])
+	result[position] = rule
+
+	return result, nil
+}
+
+// replaceFirewallRules returns a copy of the firewall service with rules as
+// its rules. A missing service is created enabled, dropping by default.
+func replaceFirewallRules(service *FirewallService, rules []*FirewallRule) *FirewallService {
+	if service == nil {
+		return &FirewallService{
+			IsEnabled:     true,
+			DefaultAction: "drop",
+			FirewallRule:  rules,
+		}
+	}
+
+	newService := *service
+	newService.FirewallRule = rules
+	return &newService
+}
@@ -0,0 +1,125 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestFindFirewallRuleID(t *testing.T) {
+	a := &FirewallRule{ID: "1", Description: "a"}
+	b := &FirewallRule{ID: "2", Description: "b"}
+	rule := &FirewallRule{Description: "new"}
+	other := &FirewallRule{Description: "new"}
+
+	cases := []struct {
+		name     string
+		rules    []*FirewallRule
+		sent     []*FirewallRule
+		expected string
+	}{
+		{"only rule", []*FirewallRule{{ID: "3"}}, []*FirewallRule{rule}, "3"},
+		{"in between", []*FirewallRule{a, {ID: "3"}, b}, []*FirewallRule{a, rule, b}, "3"},
+		{"same attributes batched", []*FirewallRule{{ID: "3"}, a, {ID: "4"}}, []*FirewallRule{other, a, rule}, "4"},
+		{"not sent", []*FirewallRule{a, {ID: "3"}}, []*FirewallRule{a, other}, ""},
+		{"rules changed meanwhile", []*FirewallRule{a, b}, []*FirewallRule{a, rule, b}, ""},
+		{"existing Id", []*FirewallRule{a, b}, []*FirewallRule{rule, a}, ""},
+	}
+
+	for _, c := range cases {
+		actual, err := findFirewallRuleID(c.rules, c.sent, rule)
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", c.name, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+		} else if actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func testAccCheckVcdFirewallRuleDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_firewall_rule" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		id := rs.Primary.Attributes["rule_id"]
+		if _, rule := findFirewallRule(firewallRulesOf(edgeGatewayServices(edgeGateway).FirewallService), id); rule != nil {
+			return fmt.Errorf("Firewall rule %s still exists", id)
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdFirewallRule_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdFirewallRuleDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdFirewallRule_basic, edgeGateway, "22", edgeGateway),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"vcd_firewall_rule.first", "rule_id"),
+					resource.TestCheckResourceAttrSet(
+						"vcd_firewall_rule.second", "rule_id"),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rule.first", "destination_port", "22"),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rule.second", "destination_port", "443"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdFirewallRule_basic, edgeGateway, "2222", edgeGateway),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rule.first", "destination_port", "2222"),
+				),
+			},
+		},
+	})
+}
+
+// Both rules have the same attributes apart from their port, they are told
+// apart by their position only
+const testAccCheckVcdFirewallRule_basic = `
+resource "vcd_firewall_rule" "first" {
+  edge_gateway     = "%s"
+  description      = "terraform-acc-rule"
+  policy           = "allow"
+  protocol         = "tcp"
+  destination_port = "%s"
+  destination_ip   = "10.10.102.50"
+  source_port      = "any"
+  source_ip        = "any"
+}
+
+resource "vcd_firewall_rule" "second" {
+  edge_gateway     = "%s"
+  description      = "terraform-acc-rule"
+  policy           = "allow"
+  protocol         = "tcp"
+  destination_port = "443"
+  destination_ip   = "10.10.102.50"
+  source_port      = "any"
+  source_ip        = "any"
+  after            = "${vcd_firewall_rule.first.rule_id}"
+}
+`
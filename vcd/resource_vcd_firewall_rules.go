@@ -2,20 +2,27 @@ package vcd
 
 import (
 	"fmt"
-	"log"
-	"strings"
 
-	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
-	types "github.com/vCloud/govcloudair/types/v56"
 )
 
 func resourceVcdFirewallRules() *schema.Resource {
+	ruleSchema := FirewallRuleSubresourceSchema()
+	ruleSchema["id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Computed: true,
+	}
+
 	return &schema.Resource{
 		Create: resourceVcdFirewallRulesCreate,
+		Update: resourceVcdFirewallRulesUpdate,
 		Delete: resourceFirewallRulesDelete,
 		Read:   resourceFirewallRulesRead,
 
+		SchemaVersion: 1,
+		MigrateState:  resourceVcdFirewallRulesMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"edge_gateway": &schema.Schema{
 				Type:     schema.TypeString,
@@ -23,59 +30,28 @@ func resourceVcdFirewallRules() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
 			"default_action": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
+			},
+
+			"log_default_action": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
 			},
 
 			"rule": &schema.Schema{
 				Type:     schema.TypeList,
 				Optional: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"id": &schema.Schema{
-							Type:     schema.TypeString,
-							Optional: true,
-							Computed: true,
-						},
-
-						"description": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
-						},
-
-						"policy": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
-						},
-
-						"protocol": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
-						},
-
-						"destination_port": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
-						},
-
-						"destination_ip": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
-						},
-
-						"source_port": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
-						},
-
-						"source_ip": &schema.Schema{
-							Type:     schema.TypeString,
-							Required: true,
-						},
-					},
+					Schema: ruleSchema,
 				},
 			},
 		},
@@ -83,118 +59,85 @@ func resourceVcdFirewallRules() *schema.Resource {
 }
 
 func resourceVcdFirewallRulesCreate(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
-
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			FirewallService: expandFirewallService(d),
+		}, nil
+	}, meta)
 	if err != nil {
-		return fmt.Errorf("Unable to find edge gateway: %s", err)
+		return fmt.Errorf("Error setting firewall rules: %#v", err)
 	}
 
-	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		edgeGateway.Refresh()
-		firewallRules, _ := expandFirewallRules(d, edgeGateway.EdgeGateway)
-		task, err := edgeGateway.CreateFirewallRules(d.Get("default_action").(string), firewallRules)
-		if err != nil {
-			log.Printf("[INFO] Error setting firewall rules: %s", err)
-			return resource.RetryableError(
-				fmt.Errorf("Error setting firewall rules: %#v", err))
-		}
-
-		return resource.RetryableError(task.WaitTaskCompletion())
-	})
+	d.SetId(edgeGateway.HREF)
+
+	return resourceFirewallRulesRead(d, meta)
+}
+
+func resourceVcdFirewallRulesUpdate(d *schema.ResourceData, meta interface{}) error {
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			FirewallService: expandFirewallService(d),
+		}, nil
+	}, meta)
 	if err != nil {
-		return fmt.Errorf("Error completing tasks: %#v", err)
+		return fmt.Errorf("Error updating firewall rules: %#v", err)
 	}
 
-	d.SetId(d.Get("edge_gateway").(string))
-
 	return resourceFirewallRulesRead(d, meta)
 }
 
 func resourceFirewallRulesDelete(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
-
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
-
-	firewallRules := deleteFirewallRules(d, edgeGateway.EdgeGateway)
-	defaultAction := edgeGateway.EdgeGateway.Configuration.EdgeGatewayServiceConfiguration.FirewallService.DefaultAction
-	task, err := edgeGateway.CreateFirewallRules(defaultAction, firewallRules)
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			FirewallService: replaceFirewallRules(services.FirewallService, nil),
+		}, nil
+	}, meta)
 	if err != nil {
 		return fmt.Errorf("Error deleting firewall rules: %#v", err)
 	}
 
-	err = task.WaitTaskCompletion()
-	if err != nil {
-		return fmt.Errorf("Error completing tasks: %#v", err)
-	}
-
 	return nil
 }
 
+// resourceFirewallRulesRead reads all the rules of the edge gateway, so rules
+// added outside of Terraform show up as a difference.
 func resourceFirewallRulesRead(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
 	if err != nil {
 		return fmt.Errorf("Error finding edge gateway: %#v", err)
 	}
-	ruleList := d.Get("rule").([]interface{})
-	firewallRules := *edgeGateway.EdgeGateway.Configuration.EdgeGatewayServiceConfiguration.FirewallService
-	rulesCount := d.Get("rule.#").(int)
-	for i := 0; i < rulesCount; i++ {
-		prefix := fmt.Sprintf("rule.%d", i)
-		if d.Get(prefix+".id").(string) == "" {
-			log.Printf("[INFO] Rule %d has no id. Searching...", i)
-			ruleid, err := matchFirewallRule(d, prefix, firewallRules.FirewallRule)
-			if err == nil {
-				currentRule := ruleList[i].(map[string]interface{})
-				currentRule["id"] = ruleid
-				ruleList[i] = currentRule
-			}
-		}
+
+	firewallService := edgeGatewayServices(edgeGateway).FirewallService
+	if firewallService == nil {
+		firewallService = &FirewallService{}
+	}
+
+	ruleList := make([]interface{}, 0, len(firewallService.FirewallRule))
+	for _, rule := range firewallService.FirewallRule {
+		ruleList = append(ruleList, flattenFirewallRule(rule))
 	}
 	d.Set("rule", ruleList)
-	d.Set("default_action", firewallRules.DefaultAction)
+	d.Set("enabled", firewallService.IsEnabled)
+	d.Set("default_action", firewallService.DefaultAction)
+	d.Set("log_default_action", firewallService.LogDefaultAction)
 
 	return nil
 }
 
-func deleteFirewallRules(d *schema.ResourceData, gateway *types.EdgeGateway) []*types.FirewallRule {
-	firewallRules := gateway.Configuration.EdgeGatewayServiceConfiguration.FirewallService.FirewallRule
+// expandFirewallService builds the firewall service with exactly the
+// configured rules, in order. Rules are sent without their Ids, vCD numbers
+// them again.
+func expandFirewallService(d *schema.ResourceData) *FirewallService {
 	rulesCount := d.Get("rule.#").(int)
-	fwrules := make([]*types.FirewallRule, 0, len(firewallRules)-rulesCount)
-
-	for _, f := range firewallRules {
-		keep := true
-		for i := 0; i < rulesCount; i++ {
-			if d.Get(fmt.Sprintf("rule.%d.id", i)).(string) != f.ID {
-				continue
-			}
-			keep = false
-		}
-		if keep {
-			fwrules = append(fwrules, f)
-		}
+	firewallRules := make([]*FirewallRule, 0, rulesCount)
+	for i := 0; i < rulesCount; i++ {
+		firewallRules = append(firewallRules, expandFirewallRule(d, fmt.Sprintf("rule.%d.", i)))
 	}
-	return fwrules
-}
 
-func matchFirewallRule(d *schema.ResourceData, prefix string, rules []*types.FirewallRule) (string, error) {
-
-	for _, m := range rules {
-		if d.Get(prefix+".description").(string) == m.Description &&
-			d.Get(prefix+".policy").(string) == m.Policy &&
-			strings.ToLower(d.Get(prefix+".protocol").(string)) == getProtocol(*m.Protocols) &&
-			strings.ToLower(d.Get(prefix+".destination_port").(string)) == getPortString(m.Port) &&
-			strings.ToLower(d.Get(prefix+".destination_ip").(string)) == strings.ToLower(m.DestinationIP) &&
-			strings.ToLower(d.Get(prefix+".source_port").(string)) == getPortString(m.SourcePort) &&
-			strings.ToLower(d.Get(prefix+".source_ip").(string)) == strings.ToLower(m.SourceIP) {
-			return m.ID, nil
-		}
+	return &FirewallService{
+		IsEnabled:        d.Get("enabled").(bool),
+		DefaultAction:    d.Get("default_action").(string),
+		LogDefaultAction: d.Get("log_default_action").(bool),
+		FirewallRule:     firewallRules,
 	}
-	return "", fmt.Errorf("Unable to find rule")
 }
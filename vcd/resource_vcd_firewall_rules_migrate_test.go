@@ -0,0 +1,33 @@
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestVcdFirewallRulesMigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+
+	// should handle nil
+	is, err := resourceVcdFirewallRulesMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	is, err = resourceVcdFirewallRulesMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+
+	// should refuse unknown versions
+	_, err = resourceVcdFirewallRulesMigrateState(1, &terraform.InstanceState{}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown schema version")
+	}
+}
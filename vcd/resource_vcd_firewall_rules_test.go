@@ -0,0 +1,94 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func testAccCheckVcdFirewallRulesCount(n string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		rules := firewallRulesOf(edgeGatewayServices(edgeGateway).FirewallService)
+		if len(rules) != count {
+			return fmt.Errorf("Expected %d firewall rules, found %d", count, len(rules))
+		}
+
+		return nil
+	}
+}
+
+func TestAccVcdFirewallRules_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdFirewallRules_basic, edgeGateway, "22"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVcdFirewallRulesCount("vcd_firewall_rules.test", 2),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rules.test", "default_action", "drop"),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rules.test", "rule.#", "2"),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rules.test", "rule.0.destination_port", "22"),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rules.test", "rule.1.protocol", "other"),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rules.test", "rule.1.other_protocol", "gre"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdFirewallRules_basic, edgeGateway, "2222"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVcdFirewallRulesCount("vcd_firewall_rules.test", 2),
+					resource.TestCheckResourceAttr(
+						"vcd_firewall_rules.test", "rule.0.destination_port", "2222"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdFirewallRules_basic = `
+resource "vcd_firewall_rules" "test" {
+  edge_gateway   = "%s"
+  default_action = "drop"
+
+  rule {
+    description      = "terraform-acc-ssh"
+    policy           = "allow"
+    protocol         = "tcp"
+    destination_port = "%s"
+    destination_ip   = "10.10.102.50"
+    source_port      = "any"
+    source_ip        = "any"
+  }
+
+  rule {
+    description      = "terraform-acc-gre"
+    policy           = "allow"
+    protocol         = "other"
+    other_protocol   = "gre"
+    destination_port = "any"
+    destination_ip   = "10.10.102.50"
+    source_port      = "any"
+    source_ip        = "any"
+  }
+}
+`
@@ -0,0 +1,413 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdLBPool() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdLBPoolCreate,
+		Update: resourceVcdLBPoolUpdate,
+		Read:   resourceVcdLBPoolRead,
+		Delete: resourceVcdLBPoolDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"service_port": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"protocol": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateLBProtocol(),
+						},
+
+						"algorithm": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "ROUND_ROBIN",
+							ValidateFunc: validation.StringInSlice([]string{
+								"IP_HASH",
+								"ROUND_ROBIN",
+								"URI",
+								"LEAST_CONN",
+							}, false),
+						},
+
+						"port": &schema.Schema{
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+
+						"health_check_port": &schema.Schema{
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+
+						"health_check": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Computed: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"mode": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+										ValidateFunc: validation.StringInSlice([]string{
+											"TCP",
+											"HTTP",
+											"SSL",
+										}, false),
+									},
+
+									"uri": &schema.Schema{
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+
+									"healthy_threshold": &schema.Schema{
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  2,
+									},
+
+									"unhealthy_threshold": &schema.Schema{
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  3,
+									},
+
+									"interval": &schema.Schema{
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  5,
+									},
+
+									"timeout": &schema.Schema{
+										Type:     schema.TypeInt,
+										Optional: true,
+										Default:  15,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+
+			"member": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip_address": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: ValidateIPv4(),
+						},
+
+						"weight": &schema.Schema{
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  1,
+						},
+
+						// Overrides the ports of the pool for this member
+						"service_port": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"protocol": &schema.Schema{
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validateLBProtocol(),
+									},
+
+									"port": &schema.Schema{
+										Type:     schema.TypeInt,
+										Required: true,
+									},
+
+									"health_check_port": &schema.Schema{
+										Type:     schema.TypeInt,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVcdLBPoolCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		if findLBPool(services.LoadBalancerService, name) != nil {
+			return nil, fmt.Errorf("Edge gateway %s already has a load balancer pool named %s", edgeGateway.Name, name)
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			LoadBalancerService: replaceLBPool(services.LoadBalancerService, name, expandLBPool(d)),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding load balancer pool %s: %#v", name, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", edgeGateway.HREF, name))
+
+	return resourceVcdLBPoolRead(d, meta)
+}
+
+func resourceVcdLBPoolUpdate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			LoadBalancerService: replaceLBPool(services.LoadBalancerService, name, expandLBPool(d)),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating load balancer pool %s: %#v", name, err)
+	}
+
+	return resourceVcdLBPoolRead(d, meta)
+}
+
+func resourceVcdLBPoolRead(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	pool := findLBPool(edgeGatewayServices(edgeGateway).LoadBalancerService, d.Get("name").(string))
+	if pool == nil {
+		log.Printf("[DEBUG] Unable to find load balancer pool %s. Removing from tfstate", d.Get("name").(string))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("description", pool.Description)
+
+	// Pools always carry a port for every protocol, only the ones that are
+	// configured or enabled are of interest
+	configured := make(map[string]bool)
+	for _, port := range d.Get("service_port").([]interface{}) {
+		configured[port.(map[string]interface{})["protocol"].(string)] = true
+	}
+
+	servicePorts := make([]map[string]interface{}, 0)
+	for _, port := range pool.ServicePort {
+		if !port.IsEnabled && !configured[port.Protocol] {
+			continue
+		}
+
+		servicePort := map[string]interface{}{
+			"enabled":           port.IsEnabled,
+			"protocol":          port.Protocol,
+			"algorithm":         port.Algorithm,
+			"port":              atoiOrZero(port.Port),
+			"health_check_port": atoiOrZero(port.HealthCheckPort),
+		}
+
+		if check := port.HealthCheck; check != nil {
+			servicePort["health_check"] = []map[string]interface{}{
+				{
+					"mode":                check.Mode,
+					"uri":                 check.URI,
+					"healthy_threshold":   atoiOrZero(check.HealthThreshold),
+					"unhealthy_threshold": atoiOrZero(check.UnhealthThreshold),
+					"interval":            atoiOrZero(check.Interval),
+					"timeout":             atoiOrZero(check.Timeout),
+				},
+			}
+		}
+
+		servicePorts = append(servicePorts, servicePort)
+	}
+	d.Set("service_port", servicePorts)
+
+	members := make([]map[string]interface{}, 0, len(pool.Member))
+	for _, member := range pool.Member {
+		memberPorts := make([]map[string]interface{}, 0)
+		for _, port := range member.ServicePort {
+			if !configured[port.Protocol] {
+				continue
+			}
+			memberPorts = append(memberPorts, map[string]interface{}{
+				"protocol":          port.Protocol,
+				"port":              atoiOrZero(port.Port),
+				"health_check_port": atoiOrZero(port.HealthCheckPort),
+			})
+		}
+
+		members = append(members, map[string]interface{}{
+			"ip_address":   member.IPAddress,
+			"weight":       atoiOrZero(member.Weight),
+			"service_port": memberPorts,
+		})
+	}
+	d.Set("member", members)
+
+	return nil
+}
+
+func resourceVcdLBPoolDelete(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			LoadBalancerService: replaceLBPool(services.LoadBalancerService, name, nil),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing load balancer pool %s: %#v", name, err)
+	}
+
+	return nil
+}
+
+func expandLBPool(d *schema.ResourceData) *LoadBalancerPool {
+	pool := &LoadBalancerPool{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	}
+
+	for _, v := range d.Get("service_port").([]interface{}) {
+		data := v.(map[string]interface{})
+
+		servicePort := &types.LBPoolServicePort{
+			IsEnabled:       data["enabled"].(bool),
+			Protocol:        data["protocol"].(string),
+			Algorithm:       data["algorithm"].(string),
+			Port:            strconv.Itoa(data["port"].(int)),
+			HealthCheckPort: itoaOrEmpty(data["health_check_port"].(int)),
+		}
+
+		for _, c := range data["health_check"].([]interface{}) {
+			check := c.(map[string]interface{})
+			servicePort.HealthCheck = &types.LBPoolHealthCheck{
+				Mode:              check["mode"].(string),
+				URI:               check["uri"].(string),
+				HealthThreshold:   strconv.Itoa(check["healthy_threshold"].(int)),
+				UnhealthThreshold: strconv.Itoa(check["unhealthy_threshold"].(int)),
+				Interval:          strconv.Itoa(check["interval"].(int)),
+				Timeout:           strconv.Itoa(check["timeout"].(int)),
+			}
+		}
+
+		pool.ServicePort = append(pool.ServicePort, servicePort)
+	}
+
+	for _, v := range d.Get("member").([]interface{}) {
+		data := v.(map[string]interface{})
+
+		member := &LBPoolMember{
+			IPAddress: data["ip_address"].(string),
+			Weight:    strconv.Itoa(data["weight"].(int)),
+		}
+
+		for _, p := range data["service_port"].([]interface{}) {
+			port := p.(map[string]interface{})
+			member.ServicePort = append(member.ServicePort, &types.LBPoolServicePort{
+				Protocol:        port["protocol"].(string),
+				Port:            strconv.Itoa(port["port"].(int)),
+				HealthCheckPort: itoaOrEmpty(port["health_check_port"].(int)),
+			})
+		}
+
+		pool.Member = append(pool.Member, member)
+	}
+
+	return pool
+}
+
+func findLBPool(service *LoadBalancerService, name string) *LoadBalancerPool {
+	if service == nil {
+		return nil
+	}
+
+	for _, pool := range service.Pool {
+		if pool.Name == name {
+			return pool
+		}
+	}
+
+	return nil
+}
+
+// replaceLBPool returns the load balancer service with the pool named name
+// replaced by pool, or removed when pool is nil. Virtual servers are kept as
+// they are.
+func replaceLBPool(service *LoadBalancerService, name string, pool *LoadBalancerPool) *LoadBalancerService {
+	newService := &LoadBalancerService{}
+	if service != nil {
+		newService.VirtualServer = service.VirtualServer
+	}
+
+	found := false
+	if service != nil {
+		for _, existing := range service.Pool {
+			if existing.Name != name {
+				newService.Pool = append(newService.Pool, existing)
+				continue
+			}
+			found = true
+			if pool != nil {
+				newService.Pool = append(newService.Pool, pool)
+			}
+		}
+	}
+
+	if !found && pool != nil {
+		newService.Pool = append(newService.Pool, pool)
+	}
+
+	newService.IsEnabled = len(newService.Pool) > 0 || len(newService.VirtualServer) > 0 || (service != nil && service.IsEnabled)
+
+	return newService
+}
+
+func validateLBProtocol() schema.SchemaValidateFunc {
+	return validation.StringInSlice([]string{
+		"HTTP",
+		"HTTPS",
+		"TCP",
+	}, false)
+}
@@ -0,0 +1,116 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestReplaceLBPool(t *testing.T) {
+	web := &LoadBalancerPool{Name: "web"}
+	api := &LoadBalancerPool{Name: "api"}
+	newWeb := &LoadBalancerPool{Name: "web", Description: "new"}
+	virtualServer := &LoadBalancerVirtualServer{Name: "web", Pool: "web"}
+
+	cases := []struct {
+		name     string
+		service  *LoadBalancerService
+		pool     string
+		new      *LoadBalancerPool
+		expected *LoadBalancerService
+	}{
+		{"add to missing service", nil, "web", web,
+			&LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{web}}},
+		{"add keeps virtual servers", &LoadBalancerService{Pool: []*LoadBalancerPool{web}, VirtualServer: []*LoadBalancerVirtualServer{virtualServer}}, "api", api,
+			&LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{web, api}, VirtualServer: []*LoadBalancerVirtualServer{virtualServer}}},
+		{"replace in place", &LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{web, api}}, "web", newWeb,
+			&LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{newWeb, api}}},
+		{"remove", &LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{web, api}}, "web", nil,
+			&LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{api}}},
+		{"remove last", &LoadBalancerService{Pool: []*LoadBalancerPool{web}}, "web", nil,
+			&LoadBalancerService{}},
+	}
+
+	for _, c := range cases {
+		actual := replaceLBPool(c.service, c.pool, c.new)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func testAccCheckVcdLBPoolDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_lb_pool" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if findLBPool(edgeGatewayServices(edgeGateway).LoadBalancerService, rs.Primary.Attributes["name"]) != nil {
+			return fmt.Errorf("Load balancer pool %s still exists", rs.Primary.Attributes["name"])
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdLBPool_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdLBPoolDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdLBPool_basic, edgeGateway, 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_lb_pool.test", "service_port.#", "1"),
+					resource.TestCheckResourceAttr(
+						"vcd_lb_pool.test", "service_port.0.protocol", "HTTP"),
+					resource.TestCheckResourceAttr(
+						"vcd_lb_pool.test", "member.#", "2"),
+					resource.TestCheckResourceAttr(
+						"vcd_lb_pool.test", "member.1.weight", "1"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdLBPool_basic, edgeGateway, 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_lb_pool.test", "member.1.weight", "2"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdLBPool_basic = `
+resource "vcd_lb_pool" "test" {
+  edge_gateway = "%s"
+  name         = "terraform-acc-pool"
+
+  service_port {
+    protocol = "HTTP"
+    port     = 80
+  }
+
+  member {
+    ip_address = "10.10.0.11"
+  }
+
+  member {
+    ip_address = "10.10.0.12"
+    weight     = %d
+  }
+}
+`
@@ -0,0 +1,335 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdLBVirtualServer() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdLBVirtualServerCreate,
+		Update: resourceVcdLBVirtualServerUpdate,
+		Read:   resourceVcdLBVirtualServerRead,
+		Delete: resourceVcdLBVirtualServerDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			// Name of the network connected to the gateway interface
+			"interface": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"ip_address": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: ValidateIPv4(),
+			},
+
+			"pool": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"logging": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"service_profile": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+
+						"protocol": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateLBProtocol(),
+						},
+
+						"port": &schema.Schema{
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+
+						"persistence": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"method": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+										ValidateFunc: validation.StringInSlice([]string{
+											"COOKIE",
+											"SSL_SESSION_ID",
+										}, false),
+									},
+
+									"cookie_name": &schema.Schema{
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+
+									"cookie_mode": &schema.Schema{
+										Type:     schema.TypeString,
+										Optional: true,
+										ValidateFunc: validation.StringInSlice([]string{
+											"INSERT",
+											"PREFIX",
+											"APP",
+										}, false),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceVcdLBVirtualServerCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		if findLBVirtualServer(services.LoadBalancerService, name) != nil {
+			return nil, fmt.Errorf("Edge gateway %s already has a load balancer virtual server named %s", edgeGateway.Name, name)
+		}
+
+		virtualServer, err := expandLBVirtualServer(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			LoadBalancerService: replaceLBVirtualServer(services.LoadBalancerService, name, virtualServer),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding load balancer virtual server %s: %#v", name, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", edgeGateway.HREF, name))
+
+	return resourceVcdLBVirtualServerRead(d, meta)
+}
+
+func resourceVcdLBVirtualServerUpdate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		virtualServer, err := expandLBVirtualServer(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			LoadBalancerService: replaceLBVirtualServer(services.LoadBalancerService, name, virtualServer),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating load balancer virtual server %s: %#v", name, err)
+	}
+
+	return resourceVcdLBVirtualServerRead(d, meta)
+}
+
+func resourceVcdLBVirtualServerRead(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	virtualServer := findLBVirtualServer(edgeGatewayServices(edgeGateway).LoadBalancerService, d.Get("name").(string))
+	if virtualServer == nil {
+		log.Printf("[DEBUG] Unable to find load balancer virtual server %s. Removing from tfstate", d.Get("name").(string))
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("description", virtualServer.Description)
+	d.Set("enabled", virtualServer.IsEnabled)
+	d.Set("ip_address", virtualServer.IPAddress)
+	d.Set("pool", virtualServer.Pool)
+	d.Set("logging", virtualServer.Logging)
+	if virtualServer.Interface != nil {
+		d.Set("interface", virtualServer.Interface.Name)
+	}
+
+	// Virtual servers always carry a profile for every protocol, only the
+	// ones that are configured or enabled are of interest
+	configured := make(map[string]bool)
+	for _, profile := range d.Get("service_profile").([]interface{}) {
+		configured[profile.(map[string]interface{})["protocol"].(string)] = true
+	}
+
+	serviceProfiles := make([]map[string]interface{}, 0)
+	for _, profile := range virtualServer.ServiceProfile {
+		if !profile.IsEnabled && !configured[profile.Protocol] {
+			continue
+		}
+
+		serviceProfile := map[string]interface{}{
+			"enabled":  profile.IsEnabled,
+			"protocol": profile.Protocol,
+			"port":     atoiOrZero(profile.Port),
+		}
+
+		if persistence := profile.Persistence; persistence != nil && persistence.Method != "" {
+			serviceProfile["persistence"] = []map[string]interface{}{
+				{
+					"method":      persistence.Method,
+					"cookie_name": persistence.CookieName,
+					"cookie_mode": persistence.CookieMode,
+				},
+			}
+		}
+
+		serviceProfiles = append(serviceProfiles, serviceProfile)
+	}
+	d.Set("service_profile", serviceProfiles)
+
+	return nil
+}
+
+func resourceVcdLBVirtualServerDelete(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		return &EdgeGatewayServiceConfiguration{
+			LoadBalancerService: replaceLBVirtualServer(services.LoadBalancerService, name, nil),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing load balancer virtual server %s: %#v", name, err)
+	}
+
+	return nil
+}
+
+func expandLBVirtualServer(d *schema.ResourceData, edgeGateway *EdgeGateway) (*LoadBalancerVirtualServer, error) {
+	network, err := findGatewayInterfaceNetwork(edgeGateway, d.Get("interface").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	virtualServer := &LoadBalancerVirtualServer{
+		IsEnabled:   d.Get("enabled").(bool),
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Interface: &types.Reference{
+			HREF: network.HREF,
+			Name: network.Name,
+			Type: network.Type,
+		},
+		IPAddress: d.Get("ip_address").(string),
+		Logging:   d.Get("logging").(bool),
+		Pool:      d.Get("pool").(string),
+	}
+
+	for _, v := range d.Get("service_profile").([]interface{}) {
+		data := v.(map[string]interface{})
+
+		profile := &types.LBVirtualServerServiceProfile{
+			IsEnabled: data["enabled"].(bool),
+			Protocol:  data["protocol"].(string),
+			Port:      strconv.Itoa(data["port"].(int)),
+		}
+
+		for _, p := range data["persistence"].([]interface{}) {
+			persistence := p.(map[string]interface{})
+			profile.Persistence = &types.LBPersistence{
+				Method:     persistence["method"].(string),
+				CookieName: persistence["cookie_name"].(string),
+				CookieMode: persistence["cookie_mode"].(string),
+			}
+		}
+
+		virtualServer.ServiceProfile = append(virtualServer.ServiceProfile, profile)
+	}
+
+	return virtualServer, nil
+}
+
+func findLBVirtualServer(service *LoadBalancerService, name string) *LoadBalancerVirtualServer {
+	if service == nil {
+		return nil
+	}
+
+	for _, virtualServer := range service.VirtualServer {
+		if virtualServer.Name == name {
+			return virtualServer
+		}
+	}
+
+	return nil
+}
+
+// replaceLBVirtualServer returns the load balancer service with the virtual
+// server named name replaced by virtualServer, or removed when virtualServer
+// is nil. Pools are kept as they are.
+func replaceLBVirtualServer(service *LoadBalancerService, name string, virtualServer *LoadBalancerVirtualServer) *LoadBalancerService {
+	newService := &LoadBalancerService{}
+	if service != nil {
+		newService.Pool = service.Pool
+	}
+
+	found := false
+	if service != nil {
+		for _, existing := range service.VirtualServer {
+			if existing.Name != name {
+				newService.VirtualServer = append(newService.VirtualServer, existing)
+				continue
+			}
+			found = true
+			if virtualServer != nil {
+				newService.VirtualServer = append(newService.VirtualServer, virtualServer)
+			}
+		}
+	}
+
+	if !found && virtualServer != nil {
+		newService.VirtualServer = append(newService.VirtualServer, virtualServer)
+	}
+
+	newService.IsEnabled = len(newService.Pool) > 0 || len(newService.VirtualServer) > 0 || (service != nil && service.IsEnabled)
+
+	return newService
+}
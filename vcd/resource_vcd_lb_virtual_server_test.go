@@ -0,0 +1,140 @@
+package vcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestLBVirtualServerDisabled(t *testing.T) {
+	output, err := xml.Marshal(&LoadBalancerVirtualServer{Name: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(output), "<IsEnabled>false</IsEnabled>") {
+		t.Errorf("Expected a disabled virtual server in %s", output)
+	}
+}
+
+func TestReplaceLBVirtualServer(t *testing.T) {
+	web := &LoadBalancerVirtualServer{Name: "web", Pool: "web"}
+	api := &LoadBalancerVirtualServer{Name: "api", Pool: "api"}
+	newWeb := &LoadBalancerVirtualServer{Name: "web", Pool: "api"}
+	pool := &LoadBalancerPool{Name: "web"}
+
+	cases := []struct {
+		name          string
+		service       *LoadBalancerService
+		virtualServer string
+		new           *LoadBalancerVirtualServer
+		expected      *LoadBalancerService
+	}{
+		{"add to missing service", nil, "web", web,
+			&LoadBalancerService{IsEnabled: true, VirtualServer: []*LoadBalancerVirtualServer{web}}},
+		{"add keeps pools", &LoadBalancerService{Pool: []*LoadBalancerPool{pool}, VirtualServer: []*LoadBalancerVirtualServer{web}}, "api", api,
+			&LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{pool}, VirtualServer: []*LoadBalancerVirtualServer{web, api}}},
+		{"replace in place", &LoadBalancerService{IsEnabled: true, VirtualServer: []*LoadBalancerVirtualServer{web, api}}, "web", newWeb,
+			&LoadBalancerService{IsEnabled: true, VirtualServer: []*LoadBalancerVirtualServer{newWeb, api}}},
+		{"remove keeps the service enabled for its pools", &LoadBalancerService{Pool: []*LoadBalancerPool{pool}, VirtualServer: []*LoadBalancerVirtualServer{web}}, "web", nil,
+			&LoadBalancerService{IsEnabled: true, Pool: []*LoadBalancerPool{pool}}},
+	}
+
+	for _, c := range cases {
+		actual := replaceLBVirtualServer(c.service, c.virtualServer, c.new)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func testAccCheckVcdLBVirtualServerDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_lb_virtual_server" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if findLBVirtualServer(edgeGatewayServices(edgeGateway).LoadBalancerService, rs.Primary.Attributes["name"]) != nil {
+			return fmt.Errorf("Load balancer virtual server %s still exists", rs.Primary.Attributes["name"])
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdLBVirtualServer_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+	externalNetwork := os.Getenv("VCD_EXTERNAL_NETWORK")
+	externalIP := os.Getenv("VCD_EXTERNAL_IP")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if externalNetwork == "" || externalIP == "" {
+				t.Skip("VCD_EXTERNAL_NETWORK and VCD_EXTERNAL_IP must be set for load balancer virtual server acceptance tests")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdLBVirtualServerDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdLBVirtualServer_basic, edgeGateway, edgeGateway, externalNetwork, externalIP, 80),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_lb_virtual_server.test", "pool", "terraform-acc-vs-pool"),
+					resource.TestCheckResourceAttr(
+						"vcd_lb_virtual_server.test", "ip_address", externalIP),
+					resource.TestCheckResourceAttr(
+						"vcd_lb_virtual_server.test", "service_profile.0.port", "80"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdLBVirtualServer_basic, edgeGateway, edgeGateway, externalNetwork, externalIP, 8080),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_lb_virtual_server.test", "service_profile.0.port", "8080"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdLBVirtualServer_basic = `
+resource "vcd_lb_pool" "test" {
+  edge_gateway = "%s"
+  name         = "terraform-acc-vs-pool"
+
+  service_port {
+    protocol = "HTTP"
+    port     = 80
+  }
+
+  member {
+    ip_address = "10.10.0.11"
+  }
+}
+
+resource "vcd_lb_virtual_server" "test" {
+  edge_gateway = "%s"
+  name         = "terraform-acc-vs"
+  interface    = "%s"
+  ip_address   = "%s"
+  pool         = "${vcd_lb_pool.test.name}"
+
+  service_profile {
+    protocol = "HTTP"
+    port     = %d
+  }
+}
+`
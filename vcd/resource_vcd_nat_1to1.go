@@ -0,0 +1,371 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdNat1to1() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdNat1to1Create,
+		Update: resourceVcdNat1to1Update,
+		Read:   resourceVcdNat1to1Read,
+		Delete: resourceVcdNat1to1Delete,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"internal_ip": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: ValidateIPv4(),
+			},
+
+			"external_ip": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: ValidateIPv4(),
+			},
+
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// Protocol of the inbound traffic that is translated
+			"protocol": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "any",
+				DiffSuppressFunc: suppressCaseDifferences,
+				ValidateFunc: validation.StringInSlice([]string{
+					"tcp",
+					"udp",
+					"tcpudp",
+					"icmp",
+					"any",
+				}, true),
+			},
+
+			"snat_rule_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"dnat_rule_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"inbound_firewall_rule_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"outbound_firewall_rule_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// resourceVcdNat1to1Create adds the SNAT and DNAT rule of the mapping and
+// the firewall rules allowing the traffic from and to the mapped addresses in
+// one change.
+func resourceVcdNat1to1Create(d *schema.ResourceData, meta interface{}) error {
+	var snat, dnat *types.NatRule
+	inbound, outbound := expandNat1to1FirewallRules(d)
+
+	// sent ends up with the services as sent to vCD, once every change
+	// batched with this one has been applied to them
+	var sent *GatewayFeatures
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		sent = services
+
+		var err error
+		snat, dnat, err = expandNat1to1Rules(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+
+		rules := firewallRulesOf(services.FirewallService)
+		rules = append(rules[:len(rules):len(rules)], inbound, outbound)
+
+		return &EdgeGatewayServiceConfiguration{
+			NatService:      replaceNatRules(services.NatService, "", snat, dnat),
+			FirewallService: replaceFirewallRules(services.FirewallService, rules),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding 1:1 NAT mapping: %#v", err)
+	}
+
+	natRules := edgeGatewayNatRules(edgeGateway)
+	snatID, err := findNewNatRuleID(natRules, natRulesOf(sent.NatService), snat)
+	if err != nil {
+		return fmt.Errorf("Error finding the Id of the new SNAT rule: %#v", err)
+	}
+	dnatID, err := findNewNatRuleID(natRules, natRulesOf(sent.NatService), dnat)
+	if err != nil {
+		return fmt.Errorf("Error finding the Id of the new DNAT rule: %#v", err)
+	}
+
+	firewallRules := firewallRulesOf(edgeGatewayServices(edgeGateway).FirewallService)
+	inboundID, err := findFirewallRuleID(firewallRules, firewallRulesOf(sent.FirewallService), inbound)
+	if err != nil {
+		return fmt.Errorf("Error finding the Id of the new inbound firewall rule: %#v", err)
+	}
+	outboundID, err := findFirewallRuleID(firewallRules, firewallRulesOf(sent.FirewallService), outbound)
+	if err != nil {
+		return fmt.Errorf("Error finding the Id of the new outbound firewall rule: %#v", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", snatID, dnatID))
+	d.Set("snat_rule_id", snatID)
+	d.Set("dnat_rule_id", dnatID)
+	d.Set("inbound_firewall_rule_id", inboundID)
+	d.Set("outbound_firewall_rule_id", outboundID)
+
+	return resourceVcdNat1to1Read(d, meta)
+}
+
+// resourceVcdNat1to1Update replaces both NAT rules, and the firewall rules of
+// the mapping that are still there.
+func resourceVcdNat1to1Update(d *schema.ResourceData, meta interface{}) error {
+	snatID := d.Get("snat_rule_id").(string)
+	dnatID := d.Get("dnat_rule_id").(string)
+	inboundID := d.Get("inbound_firewall_rule_id").(string)
+	outboundID := d.Get("outbound_firewall_rule_id").(string)
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		rules := edgeGatewayNatRules(edgeGateway)
+		if findNatRule(rules, snatID) == nil || findNatRule(rules, dnatID) == nil {
+			return nil, fmt.Errorf("Unable to find NAT rules %s and %s", snatID, dnatID)
+		}
+
+		snat, dnat, err := expandNat1to1Rules(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+		snat.ID = snatID
+		dnat.ID = dnatID
+
+		inbound, outbound := expandNat1to1FirewallRules(d)
+		inbound.ID = inboundID
+		outbound.ID = outboundID
+
+		firewallRules := append([]*FirewallRule{}, firewallRulesOf(services.FirewallService)...)
+		for _, rule := range []*FirewallRule{inbound, outbound} {
+			if i, _ := findFirewallRule(firewallRules, rule.ID); rule.ID != "" && i >= 0 {
+				firewallRules[i] = rule
+			}
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			NatService:      replaceNatRules(replaceNatRules(services.NatService, snatID, snat), dnatID, dnat),
+			FirewallService: replaceFirewallRules(services.FirewallService, firewallRules),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating 1:1 NAT mapping: %#v", err)
+	}
+
+	return resourceVcdNat1to1Read(d, meta)
+}
+
+// resourceVcdNat1to1Read reads both halves of the mapping. When one of them is
+// gone the mapping is removed from the state, so it is created again.
+func resourceVcdNat1to1Read(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	rules := edgeGatewayNatRules(edgeGateway)
+	snat := findNatRule(rules, d.Get("snat_rule_id").(string))
+	dnat := findNatRule(rules, d.Get("dnat_rule_id").(string))
+	if snat == nil || snat.GatewayNatRule == nil || dnat == nil || dnat.GatewayNatRule == nil {
+		log.Printf("[DEBUG] Unable to find both NAT rules of 1:1 mapping %s. Removing from tfstate", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("internal_ip", nat1to1Value(d, "internal_ip", dnat.GatewayNatRule.TranslatedIP, snat.GatewayNatRule.OriginalIP))
+	d.Set("external_ip", nat1to1Value(d, "external_ip", dnat.GatewayNatRule.OriginalIP, snat.GatewayNatRule.TranslatedIP))
+	d.Set("description", nat1to1Value(d, "description", dnat.Description, snat.Description))
+	d.Set("protocol", dnat.GatewayNatRule.Protocol)
+
+	return nil
+}
+
+// nat1to1Value returns the value of the attribute key as read from the DNAT
+// and SNAT rule of the mapping. When they disagree the one that differs from
+// the state is returned, so a change to either rule shows up as a difference.
+func nat1to1Value(d *schema.ResourceData, key, dnatValue, snatValue string) string {
+	if dnatValue == d.Get(key).(string) {
+		return snatValue
+	}
+	return dnatValue
+}
+
+// resourceVcdNat1to1Delete removes the NAT and firewall rules of the mapping
+// by their Id in one change. Nothing is sent when all of them are gone.
+func resourceVcdNat1to1Delete(d *schema.ResourceData, meta interface{}) error {
+	snatID := d.Get("snat_rule_id").(string)
+	dnatID := d.Get("dnat_rule_id").(string)
+	firewallIDs := []string{d.Get("inbound_firewall_rule_id").(string), d.Get("outbound_firewall_rule_id").(string)}
+
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		natRules := natRulesOf(services.NatService)
+		removeNat := findNatRule(natRules, snatID) != nil || findNatRule(natRules, dnatID) != nil
+
+		firewallRules := firewallRulesOf(services.FirewallService)
+		removeFirewall := false
+		for _, id := range firewallIDs {
+			if i, _ := findFirewallRule(firewallRules, id); id != "" && i >= 0 {
+				firewallRules = append(firewallRules[:i:i], firewallRules[i+1:]...)
+				removeFirewall = true
+			}
+		}
+
+		if !removeNat && !removeFirewall {
+			return nil, nil
+		}
+
+		configuration := &EdgeGatewayServiceConfiguration{}
+		if removeNat {
+			configuration.NatService = replaceNatRules(replaceNatRules(services.NatService, snatID), dnatID)
+		}
+		if removeFirewall {
+			configuration.FirewallService = replaceFirewallRules(services.FirewallService, firewallRules)
+		}
+		return configuration, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing 1:1 NAT mapping: %#v", err)
+	}
+
+	return nil
+}
+
+// expandNat1to1Rules builds the SNAT and DNAT rule of the mapping, on the
+// uplink interface of the edge gateway.
+func expandNat1to1Rules(d *schema.ResourceData, edgeGateway *EdgeGateway) (*types.NatRule, *types.NatRule, error) {
+	uplink, err := findUplinkInterfaceNetwork(edgeGateway)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snat := &types.NatRule{
+		Description: d.Get("description").(string),
+		RuleType:    "SNAT",
+		IsEnabled:   true,
+		GatewayNatRule: &types.GatewayNatRule{
+			Interface: &types.Reference{
+				HREF: uplink.HREF,
+				Name: uplink.Name,
+			},
+			OriginalIP:   d.Get("internal_ip").(string),
+			TranslatedIP: d.Get("external_ip").(string),
+			Protocol:     "any",
+		},
+	}
+
+	dnat := &types.NatRule{
+		Description: d.Get("description").(string),
+		RuleType:    "DNAT",
+		IsEnabled:   true,
+		GatewayNatRule: &types.GatewayNatRule{
+			Interface: &types.Reference{
+				HREF: uplink.HREF,
+				Name: uplink.Name,
+			},
+			OriginalIP:     d.Get("external_ip").(string),
+			OriginalPort:   "any",
+			TranslatedIP:   d.Get("internal_ip").(string),
+			TranslatedPort: "any",
+			Protocol:       strings.ToLower(d.Get("protocol").(string)),
+		},
+	}
+
+	return snat, dnat, nil
+}
+
+// expandNat1to1FirewallRules builds the firewall rules of the mapping. The
+// inbound rule allows the traffic to the external IP address the DNAT rule
+// translates, the outbound rule all traffic from the internal IP address.
+func expandNat1to1FirewallRules(d *schema.ResourceData) (*FirewallRule, *FirewallRule) {
+	protocols := &FirewallRuleProtocols{}
+	switch strings.ToLower(d.Get("protocol").(string)) {
+	case "tcp":
+		protocols.TCP = true
+	case "udp":
+		protocols.UDP = true
+	case "tcpudp":
+		protocols.TCP = true
+		protocols.UDP = true
+	case "icmp":
+		protocols.ICMP = true
+	default:
+		protocols.Any = true
+	}
+
+	inbound := &FirewallRule{
+		IsEnabled:            true,
+		Description:          d.Get("description").(string),
+		Policy:               "allow",
+		Protocols:            protocols,
+		Port:                 -1,
+		DestinationPortRange: "Any",
+		DestinationIP:        d.Get("external_ip").(string),
+		SourcePort:           -1,
+		SourcePortRange:      "Any",
+		SourceIP:             "Any",
+	}
+
+	outbound := &FirewallRule{
+		IsEnabled:            true,
+		Description:          d.Get("description").(string),
+		Policy:               "allow",
+		Protocols:            &FirewallRuleProtocols{Any: true},
+		Port:                 -1,
+		DestinationPortRange: "Any",
+		DestinationIP:        "Any",
+		SourcePort:           -1,
+		SourcePortRange:      "Any",
+		SourceIP:             d.Get("internal_ip").(string),
+	}
+
+	return inbound, outbound
+}
+
+// findUplinkInterfaceNetwork returns the network of the last uplink interface
+// of the edge gateway, the interface the rules of the mapping are bound to.
+func findUplinkInterfaceNetwork(edgeGateway *EdgeGateway) (*types.Reference, error) {
+	var uplink *types.Reference
+	if edgeGateway.Configuration != nil && edgeGateway.Configuration.GatewayInterfaces != nil {
+		for _, gatewayInterface := range edgeGateway.Configuration.GatewayInterfaces.GatewayInterface {
+			if gatewayInterface.InterfaceType == "uplink" && gatewayInterface.Network != nil {
+				uplink = gatewayInterface.Network
+			}
+		}
+	}
+
+	if uplink == nil {
+		return nil, fmt.Errorf("Edge gateway %s has no uplink interface", edgeGateway.Name)
+	}
+
+	return uplink, nil
+}
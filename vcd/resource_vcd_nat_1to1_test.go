@@ -0,0 +1,129 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestExpandNat1to1FirewallRules(t *testing.T) {
+	cases := []struct {
+		protocol  string
+		protocols *FirewallRuleProtocols
+	}{
+		{"any", &FirewallRuleProtocols{Any: true}},
+		{"tcp", &FirewallRuleProtocols{TCP: true}},
+		{"UDP", &FirewallRuleProtocols{UDP: true}},
+		{"tcpudp", &FirewallRuleProtocols{TCP: true, UDP: true}},
+		{"icmp", &FirewallRuleProtocols{ICMP: true}},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceVcdNat1to1().Schema, map[string]interface{}{
+			"internal_ip": "10.10.0.5",
+			"external_ip": "203.0.113.10",
+			"description": "web",
+			"protocol":    c.protocol,
+		})
+
+		inbound, outbound := expandNat1to1FirewallRules(d)
+
+		expected := &FirewallRule{IsEnabled: true, Description: "web", Policy: "allow", Protocols: c.protocols,
+			Port: -1, DestinationPortRange: "Any", DestinationIP: "203.0.113.10", SourcePort: -1, SourcePortRange: "Any", SourceIP: "Any"}
+		if !reflect.DeepEqual(inbound, expected) {
+			t.Errorf("%s: expected inbound rule %#v, got %#v", c.protocol, expected, inbound)
+		}
+
+		expected = &FirewallRule{IsEnabled: true, Description: "web", Policy: "allow", Protocols: &FirewallRuleProtocols{Any: true},
+			Port: -1, DestinationPortRange: "Any", DestinationIP: "Any", SourcePort: -1, SourcePortRange: "Any", SourceIP: "10.10.0.5"}
+		if !reflect.DeepEqual(outbound, expected) {
+			t.Errorf("%s: expected outbound rule %#v, got %#v", c.protocol, expected, outbound)
+		}
+	}
+}
+
+func testAccCheckVcdNat1to1Destroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_nat_1to1" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		rules := edgeGatewayNatRules(edgeGateway)
+		for _, id := range []string{rs.Primary.Attributes["snat_rule_id"], rs.Primary.Attributes["dnat_rule_id"]} {
+			if findNatRule(rules, id) != nil {
+				return fmt.Errorf("NAT rule %s still exists", id)
+			}
+		}
+
+		firewallRules := firewallRulesOf(edgeGatewayServices(edgeGateway).FirewallService)
+		for _, id := range []string{rs.Primary.Attributes["inbound_firewall_rule_id"], rs.Primary.Attributes["outbound_firewall_rule_id"]} {
+			if i, _ := findFirewallRule(firewallRules, id); i >= 0 {
+				return fmt.Errorf("Firewall rule %s still exists", id)
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdNat1to1_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+	externalIP := os.Getenv("VCD_EXTERNAL_IP")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if externalIP == "" {
+				t.Skip("VCD_EXTERNAL_IP must be set for 1:1 NAT acceptance tests")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdNat1to1Destroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdNat1to1_basic, edgeGateway, externalIP, "any"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(
+						"vcd_nat_1to1.test", "snat_rule_id"),
+					resource.TestCheckResourceAttrSet(
+						"vcd_nat_1to1.test", "dnat_rule_id"),
+					resource.TestCheckResourceAttrSet(
+						"vcd_nat_1to1.test", "inbound_firewall_rule_id"),
+					resource.TestCheckResourceAttrSet(
+						"vcd_nat_1to1.test", "outbound_firewall_rule_id"),
+					resource.TestCheckResourceAttr(
+						"vcd_nat_1to1.test", "external_ip", externalIP),
+					resource.TestCheckResourceAttr(
+						"vcd_nat_1to1.test", "protocol", "any"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdNat1to1_basic, edgeGateway, externalIP, "tcp"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_nat_1to1.test", "protocol", "tcp"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdNat1to1_basic = `
+resource "vcd_nat_1to1" "test" {
+  edge_gateway = "%s"
+  internal_ip  = "10.10.102.60"
+  external_ip  = "%s"
+  description  = "terraform-acc-1to1"
+  protocol     = "%s"
+}
+`
@@ -0,0 +1,273 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdNatRule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdNatRuleCreate,
+		Update: resourceVcdNatRuleUpdate,
+		Read:   resourceVcdNatRuleRead,
+		Delete: resourceVcdNatRuleDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edge_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"rule_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"SNAT",
+					"DNAT",
+				}, false),
+			},
+
+			// Name of the network connected to the gateway interface the
+			// rule is applied to
+			"network_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"description": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"original_ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"original_port": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "any",
+				DiffSuppressFunc: suppressCaseDifferences,
+			},
+
+			"translated_ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"translated_port": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "any",
+				DiffSuppressFunc: suppressCaseDifferences,
+			},
+
+			"protocol": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "any",
+				DiffSuppressFunc: suppressCaseDifferences,
+				ValidateFunc: validation.StringInSlice([]string{
+					"tcp",
+					"udp",
+					"tcpudp",
+					"icmp",
+					"any",
+				}, true),
+			},
+
+			"icmp_sub_type": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceVcdNatRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	// sent ends up with the services as sent to vCD, see
+	// resourceVcdFirewallRuleCreate
+	var sent *GatewayFeatures
+	var rule *types.NatRule
+
+	edgeGateway, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		sent = services
+
+		var err error
+		rule, err = expandNatRule(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			NatService: replaceNatRules(services.NatService, "", rule),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding NAT rule: %#v", err)
+	}
+
+	id, err := findNewNatRuleID(edgeGatewayNatRules(edgeGateway), natRulesOf(sent.NatService), rule)
+	if err != nil {
+		return fmt.Errorf("Error finding the new NAT rule: %#v", err)
+	}
+
+	d.SetId(id)
+
+	return resourceVcdNatRuleRead(d, meta)
+}
+
+func resourceVcdNatRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		if findNatRule(edgeGatewayNatRules(edgeGateway), d.Id()) == nil {
+			return nil, fmt.Errorf("Unable to find NAT rule %s", d.Id())
+		}
+
+		rule, err := expandNatRule(d, edgeGateway)
+		if err != nil {
+			return nil, err
+		}
+		rule.ID = d.Id()
+
+		return &EdgeGatewayServiceConfiguration{
+			NatService: replaceNatRules(services.NatService, d.Id(), rule),
+		}, nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating NAT rule %s: %#v", d.Id(), err)
+	}
+
+	return resourceVcdNatRuleRead(d, meta)
+}
+
+func resourceVcdNatRuleRead(d *schema.ResourceData, meta interface{}) error {
+	edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding edge gateway: %#v", err)
+	}
+
+	rule := findNatRule(edgeGatewayNatRules(edgeGateway), d.Id())
+	if rule == nil || rule.GatewayNatRule == nil {
+		log.Printf("[DEBUG] Unable to find NAT rule %s. Removing from tfstate", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("rule_type", rule.RuleType)
+	d.Set("description", rule.Description)
+	d.Set("enabled", rule.IsEnabled)
+	if rule.GatewayNatRule.Interface != nil {
+		d.Set("network_name", rule.GatewayNatRule.Interface.Name)
+	}
+	d.Set("original_ip", rule.GatewayNatRule.OriginalIP)
+	d.Set("original_port", rule.GatewayNatRule.OriginalPort)
+	d.Set("translated_ip", rule.GatewayNatRule.TranslatedIP)
+	d.Set("translated_port", rule.GatewayNatRule.TranslatedPort)
+	d.Set("protocol", rule.GatewayNatRule.Protocol)
+	d.Set("icmp_sub_type", rule.GatewayNatRule.IcmpSubType)
+
+	return nil
+}
+
+func resourceVcdNatRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), natRuleRemoval(d.Id()), meta)
+	if err != nil {
+		return fmt.Errorf("Error removing NAT rule %s: %#v", d.Id(), err)
+	}
+
+	return nil
+}
+
+func expandNatRule(d *schema.ResourceData, edgeGateway *EdgeGateway) (*types.NatRule, error) {
+	network, err := findGatewayInterfaceNetwork(edgeGateway, d.Get("network_name").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.NatRule{
+		Description: d.Get("description").(string),
+		RuleType:    d.Get("rule_type").(string),
+		IsEnabled:   d.Get("enabled").(bool),
+		GatewayNatRule: &types.GatewayNatRule{
+			Interface: &types.Reference{
+				HREF: network.HREF,
+				Name: network.Name,
+			},
+			OriginalIP:     d.Get("original_ip").(string),
+			OriginalPort:   d.Get("original_port").(string),
+			TranslatedIP:   d.Get("translated_ip").(string),
+			TranslatedPort: d.Get("translated_port").(string),
+			Protocol:       d.Get("protocol").(string),
+			IcmpSubType:    d.Get("icmp_sub_type").(string),
+		},
+	}, nil
+}
+
+func findNatRule(rules []*types.NatRule, id string) *types.NatRule {
+	for _, rule := range rules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// natRuleRemoval returns the update removing the NAT rule with the given Id.
+// A rule that is already gone needs no reconfiguration of the edge gateway.
+func natRuleRemoval(id string) func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+	return func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		if findNatRule(natRulesOf(services.NatService), id) == nil {
+			return nil, nil
+		}
+
+		return &EdgeGatewayServiceConfiguration{
+			NatService: replaceNatRules(services.NatService, id),
+		}, nil
+	}
+}
+
+// replaceNatRules returns a copy of the NAT service with the rule with the
+// given Id replaced by rules, or removed when rules is empty. Rules are
+// added at the end when id is empty. The service is enabled whenever it has
+// rules.
+func replaceNatRules(service *types.NatService, id string, rules ...*types.NatRule) *types.NatService {
+	newService := &types.NatService{}
+	if service != nil {
+		*newService = *service
+	}
+	newService.NatRule = nil
+
+	found := false
+	if service != nil {
+		for _, existing := range service.NatRule {
+			if id == "" || existing.ID != id {
+				newService.NatRule = append(newService.NatRule, existing)
+				continue
+			}
+			found = true
+			newService.NatRule = append(newService.NatRule, rules...)
+		}
+	}
+
+	if !found {
+		newService.NatRule = append(newService.NatRule, rules...)
+	}
+
+	newService.IsEnabled = len(newService.NatRule) > 0 || newService.IsEnabled
+
+	return newService
+}
@@ -0,0 +1,142 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func TestFindNewNatRuleID(t *testing.T) {
+	a := &types.NatRule{ID: "65537", RuleType: "DNAT"}
+	b := &types.NatRule{ID: "65538", RuleType: "SNAT"}
+	rule := &types.NatRule{RuleType: "DNAT"}
+	other := &types.NatRule{RuleType: "DNAT"}
+
+	cases := []struct {
+		name     string
+		rules    []*types.NatRule
+		sent     []*types.NatRule
+		expected string
+	}{
+		{"only rule", []*types.NatRule{{ID: "65539"}}, []*types.NatRule{rule}, "65539"},
+		{"at the end", []*types.NatRule{a, b, {ID: "65539"}}, []*types.NatRule{a, b, rule}, "65539"},
+		{"same attributes batched", []*types.NatRule{a, {ID: "65539"}, {ID: "65540"}}, []*types.NatRule{a, other, rule}, "65540"},
+		{"not sent", []*types.NatRule{a, {ID: "65539"}}, []*types.NatRule{a, other}, ""},
+		{"rules changed meanwhile", []*types.NatRule{a}, []*types.NatRule{a, rule}, ""},
+		{"existing Id", []*types.NatRule{a, b}, []*types.NatRule{rule, a}, ""},
+	}
+
+	for _, c := range cases {
+		actual, err := findNewNatRuleID(c.rules, c.sent, rule)
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", c.name, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+		} else if actual != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func testAccCheckVcdNatRuleDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_nat_rule" {
+			continue
+		}
+
+		edgeGateway, err := getEdgeGateway(rs.Primary.Attributes["edge_gateway"], testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if findNatRule(edgeGatewayNatRules(edgeGateway), rs.Primary.ID) != nil {
+			return fmt.Errorf("NAT rule %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+func TestNatRuleRemoval(t *testing.T) {
+	a := &types.NatRule{ID: "65537", RuleType: "DNAT"}
+	b := &types.NatRule{ID: "65538", RuleType: "SNAT"}
+	services := &GatewayFeatures{NatService: &types.NatService{IsEnabled: true, NatRule: []*types.NatRule{a, b}}}
+
+	configuration, err := natRuleRemoval("65537")(&EdgeGateway{}, services)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configuration == nil || configuration.NatService == nil ||
+		len(configuration.NatService.NatRule) != 1 || configuration.NatService.NatRule[0] != b {
+		t.Errorf("Expected only rule 65538 to be left, got %#v", configuration)
+	}
+
+	// Nothing to change for a rule that is gone
+	for _, missing := range []*GatewayFeatures{services, {}} {
+		configuration, err = natRuleRemoval("65539")(&EdgeGateway{}, missing)
+		if err != nil || configuration != nil {
+			t.Errorf("Expected no change, got %#v and %#v", configuration, err)
+		}
+	}
+}
+
+func TestAccVcdNatRule_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+	externalNetwork := os.Getenv("VCD_EXTERNAL_NETWORK")
+	externalIP := os.Getenv("VCD_EXTERNAL_IP")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if externalNetwork == "" || externalIP == "" {
+				t.Skip("VCD_EXTERNAL_NETWORK and VCD_EXTERNAL_IP must be set for NAT rule acceptance tests")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdNatRuleDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdNatRule_basic, edgeGateway, externalNetwork, externalIP, "8443"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_nat_rule.test", "rule_type", "DNAT"),
+					resource.TestCheckResourceAttr(
+						"vcd_nat_rule.test", "network_name", externalNetwork),
+					resource.TestCheckResourceAttr(
+						"vcd_nat_rule.test", "translated_port", "8443"),
+					resource.TestCheckResourceAttr(
+						"vcd_nat_rule.test", "protocol", "tcp"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdNatRule_basic, edgeGateway, externalNetwork, externalIP, "9443"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_nat_rule.test", "translated_port", "9443"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdNatRule_basic = `
+resource "vcd_nat_rule" "test" {
+  edge_gateway    = "%s"
+  rule_type       = "DNAT"
+  network_name    = "%s"
+  description     = "terraform-acc-nat-rule"
+  original_ip     = "%s"
+  original_port   = "443"
+  translated_ip   = "10.10.102.60"
+  translated_port = "%s"
+  protocol        = "tcp"
+}
+`
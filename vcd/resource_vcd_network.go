@@ -4,21 +4,29 @@ import (
 	"log"
 
 	"bytes"
+	"encoding/xml"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vCloud/govcloudair"
 	types "github.com/vCloud/govcloudair/types/v56"
 )
 
 func resourceVcdNetwork() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVcdNetworkCreate,
+		Update: resourceVcdNetworkUpdate,
 		Read:   resourceVcdNetworkRead,
 		Delete: resourceVcdNetworkDelete,
 
+		SchemaVersion: 1,
+		MigrateState:  resourceVcdNetworkMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -30,12 +38,25 @@ func resourceVcdNetwork() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
-				Default:  "natRouted",
+				Default:  types.FenceModeNAT,
+				ValidateFunc: validation.StringInSlice([]string{
+					types.FenceModeNAT,
+					types.FenceModeIsolated,
+					types.FenceModeBridged,
+				}, false),
 			},
 
+			// Only used when fence_mode is natRouted
 			"edge_gateway": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			// Only used when fence_mode is bridged
+			"parent_network": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
 				ForceNew: true,
 			},
 
@@ -48,28 +69,25 @@ func resourceVcdNetwork() *schema.Resource {
 
 			"gateway": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 				ForceNew: true,
 			},
 
 			"dns1": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Default:  "8.8.8.8",
 			},
 
 			"dns2": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Default:  "8.8.4.4",
 			},
 
 			"dns_suffix": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 			},
 
 			"href": &schema.Schema{
@@ -83,13 +101,11 @@ func resourceVcdNetwork() *schema.Resource {
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
-				ForceNew: true,
 			},
 
 			"dhcp_pool": &schema.Schema{
 				Type:     schema.TypeSet,
 				Optional: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"start_address": &schema.Schema{
@@ -120,7 +136,6 @@ func resourceVcdNetwork() *schema.Resource {
 			"static_ip_pool": &schema.Schema{
 				Type:     schema.TypeSet,
 				Optional: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"start_address": &schema.Schema{
@@ -143,42 +158,74 @@ func resourceVcdNetwork() *schema.Resource {
 func resourceVcdNetworkCreate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
 	log.Printf("[TRACE] CLIENT: %#v", vcdClient)
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
 
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	err := validateNetworkFenceMode(d)
+	if err != nil {
+		return err
+	}
 
-	ipRanges := expandIPRange(d.Get("static_ip_pool").(*schema.Set).List())
+	fenceMode := d.Get("fence_mode").(string)
 
 	newnetwork := &types.OrgVDCNetwork{
 		Xmlns: "http://www.vmware.com/vcloud/v1.5",
 		Name:  d.Get("name").(string),
 		Configuration: &types.NetworkConfiguration{
-			FenceMode: d.Get("fence_mode").(string),
-			IPScopes: &types.IPScopes{
-				IPScope: types.IPScope{
-					IsInherited: false,
-					Gateway:     d.Get("gateway").(string),
-					Netmask:     d.Get("netmask").(string),
-					DNS1:        d.Get("dns1").(string),
-					DNS2:        d.Get("dns2").(string),
-					DNSSuffix:   d.Get("dns_suffix").(string),
-					IPRanges:    &ipRanges,
-				},
-			},
+			FenceMode:                 fenceMode,
 			BackwardCompatibilityMode: true,
 		},
-		EdgeGateway: &types.Reference{
-			HREF: edgeGateway.EdgeGateway.HREF,
-		},
 		IsShared: d.Get("shared").(bool),
 	}
 
+	if fenceMode == types.FenceModeBridged {
+		// A direct network takes its IP settings from the external network
+		parentNetwork, err := findExternalNetwork(d.Get("parent_network").(string), meta)
+		if err != nil {
+			return err
+		}
+		newnetwork.Configuration.ParentNetwork = parentNetwork
+	} else {
+		ipRanges := expandIPRange(d.Get("static_ip_pool").(*schema.Set).List())
+
+		newnetwork.Configuration.IPScopes = &types.IPScopes{
+			IPScope: types.IPScope{
+				IsInherited: false,
+				Gateway:     d.Get("gateway").(string),
+				Netmask:     d.Get("netmask").(string),
+				DNS1:        d.Get("dns1").(string),
+				DNS2:        d.Get("dns2").(string),
+				DNSSuffix:   d.Get("dns_suffix").(string),
+				IPRanges:    &ipRanges,
+			},
+		}
+	}
+
+	if fenceMode == types.FenceModeNAT {
+		edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+		if err != nil {
+			return fmt.Errorf("Error finding edge gateway: %#v", err)
+		}
+
+		newnetwork.EdgeGateway = &types.Reference{
+			HREF: edgeGateway.EdgeGateway.HREF,
+		}
+	}
+
+	if fenceMode == types.FenceModeIsolated {
+		newnetwork.ServiceConfig = expandIsolatedNetworkServiceConfig(d)
+	}
+
 	log.Printf("[INFO] NETWORK: %#v", newnetwork)
 
+	// The network is added to the edge gateway
+	if newnetwork.EdgeGateway != nil {
+		vcdClient.locks.lock(newnetwork.EdgeGateway.HREF)
+	}
 	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
 		return resource.RetryableError(vcdClient.OrgVdc.CreateOrgVDCNetwork(newnetwork))
 	})
+	if newnetwork.EdgeGateway != nil {
+		vcdClient.locks.unlock(newnetwork.EdgeGateway.HREF)
+	}
 	if err != nil {
 		return fmt.Errorf("Error: %#v", err)
 	}
@@ -193,22 +240,78 @@ func resourceVcdNetworkCreate(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Error finding network: %#v", err)
 	}
 
-	if dhcp, ok := d.GetOk("dhcp_pool"); ok {
-		err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-			task, err := edgeGateway.AddDhcpPool(network.OrgVDCNetwork, dhcp.(*schema.Set).List())
-			if err != nil {
-				return resource.RetryableError(fmt.Errorf("Error adding DHCP pool: %#v", err))
+	d.SetId(network.OrgVDCNetwork.HREF)
+
+	if _, ok := d.GetOk("dhcp_pool"); ok && fenceMode == types.FenceModeNAT {
+		err = updateNetworkDhcpPools(d, network.OrgVDCNetwork, meta)
+		if err != nil {
+			return fmt.Errorf("Error adding DHCP pool: %#v", err)
+		}
+	}
+
+	return resourceVcdNetworkRead(d, meta)
+}
+
+func resourceVcdNetworkUpdate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(d.Id())
+	defer vcdClient.locks.unlock(d.Id())
+
+	err := vcdClient.OrgVdc.Refresh()
+	if err != nil {
+		return fmt.Errorf("Error refreshing vdc: %#v", err)
+	}
+
+	network, err := getOrgVDCNetworkByHREF(d.Id(), meta)
+	if err != nil {
+		return fmt.Errorf("Error finding network: %#v", err)
+	}
+
+	fenceMode := d.Get("fence_mode").(string)
+
+	// DHCP of an isolated network is part of the network itself, so it is
+	// updated together with the IP settings
+	if d.HasChange("dns1") || d.HasChange("dns2") || d.HasChange("dns_suffix") ||
+		d.HasChange("static_ip_pool") || d.HasChange("shared") ||
+		(fenceMode == types.FenceModeIsolated && d.HasChange("dhcp_pool")) {
+		err = network.Refresh()
+		if err != nil {
+			return fmt.Errorf("Error refreshing network: %#v", err)
+		}
+
+		ipRanges := expandIPRange(d.Get("static_ip_pool").(*schema.Set).List())
+
+		newnetwork := network.OrgVDCNetwork
+		newnetwork.Xmlns = "http://www.vmware.com/vcloud/v1.5"
+		newnetwork.IsShared = d.Get("shared").(bool)
+		if fenceMode != types.FenceModeBridged {
+			if newnetwork.Configuration.IPScopes == nil {
+				newnetwork.Configuration.IPScopes = &types.IPScopes{}
 			}
+			scope := &newnetwork.Configuration.IPScopes.IPScope
+			scope.DNS1 = d.Get("dns1").(string)
+			scope.DNS2 = d.Get("dns2").(string)
+			scope.DNSSuffix = d.Get("dns_suffix").(string)
+			scope.IPRanges = &ipRanges
+		}
+		if fenceMode == types.FenceModeIsolated {
+			newnetwork.ServiceConfig = expandIsolatedNetworkServiceConfig(d)
+		}
 
-			return resource.RetryableError(task.WaitTaskCompletion())
+		err = retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+			return updateOrgVDCNetwork(newnetwork, meta)
 		})
 		if err != nil {
-			return fmt.Errorf("Error completing tasks: %#v", err)
+			return fmt.Errorf("Error updating network: %#v", err)
 		}
-
 	}
 
-	d.SetId(d.Get("name").(string))
+	if fenceMode == types.FenceModeNAT && d.HasChange("dhcp_pool") {
+		err = updateNetworkDhcpPools(d, network.OrgVDCNetwork, meta)
+		if err != nil {
+			return fmt.Errorf("Error updating DHCP pool: %#v", err)
+		}
+	}
 
 	return resourceVcdNetworkRead(d, meta)
 }
@@ -223,38 +326,160 @@ func resourceVcdNetworkRead(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Error refreshing vdc: %#v", err)
 	}
 
-	network, err := vcdClient.OrgVdc.FindVDCNetwork(d.Id())
-	if err != nil {
+	network, err := getOrgVDCNetworkByHREF(d.Id(), meta)
+	if isNotFoundError(err) {
 		log.Printf("[DEBUG] Network no longer exists. Removing from tfstate")
 		d.SetId("")
 		return nil
 	}
+	if err != nil {
+		return fmt.Errorf("Error finding network: %#v", err)
+	}
 
 	d.Set("name", network.OrgVDCNetwork.Name)
 	d.Set("href", network.OrgVDCNetwork.HREF)
 	if c := network.OrgVDCNetwork.Configuration; c != nil {
 		d.Set("fence_mode", c.FenceMode)
-		if c.IPScopes != nil {
+		if c.ParentNetwork != nil {
+			d.Set("parent_network", c.ParentNetwork.Name)
+		}
+		// Direct networks inherit their IP settings from the parent network
+		if c.IPScopes != nil && c.FenceMode != types.FenceModeBridged {
 			d.Set("gateway", c.IPScopes.IPScope.Gateway)
 			d.Set("netmask", c.IPScopes.IPScope.Netmask)
 			d.Set("dns1", c.IPScopes.IPScope.DNS1)
 			d.Set("dns2", c.IPScopes.IPScope.DNS2)
+			d.Set("dns_suffix", c.IPScopes.IPScope.DNSSuffix)
+
+			staticIPPools := make([]map[string]interface{}, 0)
+			if c.IPScopes.IPScope.IPRanges != nil {
+				for _, ipRange := range c.IPScopes.IPScope.IPRanges.IPRange {
+					staticIPPools = append(staticIPPools, map[string]interface{}{
+						"start_address": ipRange.StartAddress,
+						"end_address":   ipRange.EndAddress,
+					})
+				}
+			}
+			d.Set("static_ip_pool", staticIPPools)
+		}
+	}
+	d.Set("shared", network.OrgVDCNetwork.IsShared)
+
+	if edgeGatewayRef := network.OrgVDCNetwork.EdgeGateway; edgeGatewayRef != nil && edgeGatewayRef.Name != "" {
+		d.Set("edge_gateway", edgeGatewayRef.Name)
+	}
+
+	switch d.Get("fence_mode").(string) {
+	case types.FenceModeNAT:
+		// Pools added on the edge gateway show up as a change, even when
+		// none are configured
+		edgeGateway, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
+		if err != nil {
+			return fmt.Errorf("Error finding edge gateway: %#v", err)
+		}
+
+		var pools []*types.DhcpPoolService
+		if service := edgeGatewayServices(edgeGateway).GatewayDhcpService; service != nil {
+			pools = service.Pool
 		}
+		d.Set("dhcp_pool", flattenNetworkDhcpPools(pools, network.OrgVDCNetwork.HREF))
+	case types.FenceModeIsolated:
+		var pools []*types.DhcpPoolService
+		if serviceConfig := network.OrgVDCNetwork.ServiceConfig; serviceConfig != nil && serviceConfig.GatewayDhcpService != nil {
+			pools = serviceConfig.GatewayDhcpService.Pool
+		}
+		d.Set("dhcp_pool", flattenNetworkDhcpPools(pools, ""))
 	}
 
 	return nil
 }
 
+// flattenNetworkDhcpPools returns the DHCP pools of the network with the
+// given HREF, see isNetworkDhcpPool. An empty networkHREF matches all pools.
+func flattenNetworkDhcpPools(pools []*types.DhcpPoolService, networkHREF string) []map[string]interface{} {
+	dhcpPools := make([]map[string]interface{}, 0)
+	for _, pool := range pools {
+		if networkHREF != "" && !isNetworkDhcpPool(pool, networkHREF) {
+			continue
+		}
+
+		dhcpPools = append(dhcpPools, map[string]interface{}{
+			"start_address":      pool.LowIPAddress,
+			"end_address":        pool.HighIPAddress,
+			"default_lease_time": pool.DefaultLeaseTime,
+			"max_lease_time":     pool.MaxLeaseTime,
+		})
+	}
+
+	return dhcpPools
+}
+
+// isNetworkDhcpPool reports whether pool belongs to the network at
+// networkHREF. The edge gateway refers to networks by their admin HREF, so
+// networks are matched by ID.
+func isNetworkDhcpPool(pool *types.DhcpPoolService, networkHREF string) bool {
+	networkID := networkHREF[strings.LastIndex(networkHREF, "/")+1:]
+	return pool.Network != nil && strings.HasSuffix(pool.Network.HREF, "/"+networkID)
+}
+
+// updateNetworkDhcpPools replaces the DHCP pools of the NAT routed network on
+// its edge gateway with the configured ones.
+func updateNetworkDhcpPools(d *schema.ResourceData, network *types.OrgVDCNetwork, meta interface{}) error {
+	_, err := updateEdgeGatewayServices(d.Get("edge_gateway").(string), func(edgeGateway *EdgeGateway, services *GatewayFeatures) (*EdgeGatewayServiceConfiguration, error) {
+		pools := expandNetworkDhcpPools(d, &types.Reference{
+			HREF: network.HREF,
+			Name: network.Name,
+		})
+
+		return &EdgeGatewayServiceConfiguration{
+			GatewayDhcpService: replaceNetworkDhcpPools(services.GatewayDhcpService, network.HREF, pools),
+		}, nil
+	}, meta)
+
+	return err
+}
+
+// replaceNetworkDhcpPools returns a copy of the DHCP service with the pools
+// of the network at networkHREF replaced by pools. The service is enabled
+// whenever it has pools.
+func replaceNetworkDhcpPools(service *types.GatewayDhcpService, networkHREF string, pools []*types.DhcpPoolService) *types.GatewayDhcpService {
+	newService := &types.GatewayDhcpService{}
+	if service != nil {
+		for _, existing := range service.Pool {
+			if !isNetworkDhcpPool(existing, networkHREF) {
+				newService.Pool = append(newService.Pool, existing)
+			}
+		}
+	}
+	newService.Pool = append(newService.Pool, pools...)
+
+	newService.IsEnabled = len(newService.Pool) > 0 || (service != nil && service.IsEnabled)
+
+	return newService
+}
+
 func resourceVcdNetworkDelete(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
+	vcdClient.locks.lock(d.Id())
+	defer vcdClient.locks.unlock(d.Id())
+
+	// Removing a NAT routed network reconfigures its edge gateway
+	if d.Get("fence_mode").(string) == types.FenceModeNAT {
+		edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+		if err != nil {
+			return fmt.Errorf("Error finding edge gateway: %#v", err)
+		}
+
+		vcdClient.locks.lock(edgeGateway.EdgeGateway.HREF)
+		defer vcdClient.locks.unlock(edgeGateway.EdgeGateway.HREF)
+	}
+
 	err := vcdClient.OrgVdc.Refresh()
 	if err != nil {
 		return fmt.Errorf("Error refreshing vdc: %#v", err)
 	}
 
-	network, err := vcdClient.OrgVdc.FindVDCNetwork(d.Id())
+	network, err := getOrgVDCNetworkByHREF(d.Id(), meta)
 	if err != nil {
 		return fmt.Errorf("Error finding network: %#v", err)
 	}
@@ -274,6 +499,153 @@ func resourceVcdNetworkDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
+// validateNetworkFenceMode checks that the arguments which only apply to some
+// fence modes are used with the right one.
+func validateNetworkFenceMode(d *schema.ResourceData) error {
+	fenceMode := d.Get("fence_mode").(string)
+
+	_, hasEdgeGateway := d.GetOk("edge_gateway")
+	_, hasParentNetwork := d.GetOk("parent_network")
+	_, hasGateway := d.GetOk("gateway")
+	_, hasStaticIPPool := d.GetOk("static_ip_pool")
+	_, hasDhcpPool := d.GetOk("dhcp_pool")
+
+	switch fenceMode {
+	case types.FenceModeNAT:
+		if !hasEdgeGateway {
+			return fmt.Errorf("edge_gateway is required for %s networks", fenceMode)
+		}
+	case types.FenceModeIsolated:
+		if hasEdgeGateway {
+			return fmt.Errorf("edge_gateway can't be set for %s networks", fenceMode)
+		}
+	case types.FenceModeBridged:
+		if !hasParentNetwork {
+			return fmt.Errorf("parent_network is required for %s networks", fenceMode)
+		}
+		if hasEdgeGateway || hasGateway || hasStaticIPPool || hasDhcpPool {
+			return fmt.Errorf("edge_gateway, gateway, static_ip_pool and dhcp_pool can't be set for %s networks, the IP settings come from the parent network", fenceMode)
+		}
+		return nil
+	}
+
+	if hasParentNetwork {
+		return fmt.Errorf("parent_network can only be set for %s networks", types.FenceModeBridged)
+	}
+	if !hasGateway {
+		return fmt.Errorf("gateway is required for %s networks", fenceMode)
+	}
+
+	return nil
+}
+
+// expandIsolatedNetworkServiceConfig returns the internal DHCP service of an
+// isolated network, which has no edge gateway to run it on.
+func expandIsolatedNetworkServiceConfig(d *schema.ResourceData) *types.GatewayFeatures {
+	pools := expandNetworkDhcpPools(d, nil)
+
+	return &types.GatewayFeatures{
+		GatewayDhcpService: &types.GatewayDhcpService{
+			IsEnabled: len(pools) > 0,
+			Pool:      pools,
+		},
+	}
+}
+
+// expandNetworkDhcpPools builds the configured DHCP pools, for the given
+// network when set.
+func expandNetworkDhcpPools(d *schema.ResourceData, network *types.Reference) []*types.DhcpPoolService {
+	var pools []*types.DhcpPoolService
+	for _, v := range d.Get("dhcp_pool").(*schema.Set).List() {
+		data := v.(map[string]interface{})
+
+		pools = append(pools, &types.DhcpPoolService{
+			IsEnabled:        true,
+			Network:          network,
+			DefaultLeaseTime: data["default_lease_time"].(int),
+			MaxLeaseTime:     data["max_lease_time"].(int),
+			LowIPAddress:     data["start_address"].(string),
+			HighIPAddress:    data["end_address"].(string),
+		})
+	}
+
+	return pools
+}
+
+// findExternalNetwork returns a reference to the external network named name,
+// the parent of direct org VDC networks.
+func findExternalNetwork(name string, meta interface{}) (*types.Reference, error) {
+	results := &externalNetworkQueryResults{}
+	err := queryRecords(map[string]string{
+		"type":   "externalNetwork",
+		"format": "records",
+		"filter": "name==" + name,
+	}, results, meta)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding external network %s: %#v", name, err)
+	}
+
+	for _, record := range results.Record {
+		if record.Name == name {
+			return &types.Reference{
+				HREF: record.HREF,
+				Name: record.Name,
+				Type: "application/vnd.vmware.admin.network+xml",
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Error finding external network %s: not found", name)
+}
+
+// externalNetworkQueryResults holds the external network records of a query,
+// which govcloudair's QueryResultRecordsType has no field for.
+type externalNetworkQueryResults struct {
+	Record []struct {
+		HREF string `xml:"href,attr"`
+		Name string `xml:"name,attr"`
+	} `xml:"ExternalNetworkRecord"`
+}
+
+// getOrgVDCNetworkByHREF fetches the org VDC network found at href. Unlike
+// Vdc.FindVDCNetwork it doesn't depend on the name, which can be changed in
+// the portal.
+func getOrgVDCNetworkByHREF(href string, meta interface{}) (*govcloudair.OrgVDCNetwork, error) {
+	vcdClient := meta.(*VCDClient)
+
+	network := govcloudair.NewOrgVDCNetwork(&vcdClient.Client)
+	err := getEntity(href, network.OrgVDCNetwork, &vcdClient.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	return network, nil
+}
+
+// updateOrgVDCNetwork replaces the configuration of an org VDC network. Edits
+// have to go through the admin view of the network.
+func updateOrgVDCNetwork(network *types.OrgVDCNetwork, meta interface{}) (govcloudair.Task, error) {
+	vcdClient := meta.(*VCDClient)
+
+	output, err := xml.MarshalIndent(network, "  ", "    ")
+	if err != nil {
+		return govcloudair.Task{}, fmt.Errorf("Error marshaling network: %s", err)
+	}
+
+	pathArr := strings.Split(network.HREF, "/")
+	s, err := url.ParseRequestURI(network.HREF)
+	if err != nil {
+		return govcloudair.Task{}, fmt.Errorf("Error parsing network href %s: %s", network.HREF, err)
+	}
+	s.Path = "/api/admin/network/" + pathArr[len(pathArr)-1]
+
+	return executeRequestWithBody(string(output),
+		s.String(),
+		"PUT",
+		"application/vnd.vmware.vcloud.orgVdcNetwork+xml",
+		&vcdClient.Client)
+}
+
 func resourceVcdNetworkIPAddressHash(v interface{}) int {
 	var buf bytes.Buffer
 	m := v.(map[string]interface{})
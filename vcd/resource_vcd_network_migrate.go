@@ -0,0 +1,43 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func resourceVcdNetworkMigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found vcd_network State v0; migrating to v1")
+		return migrateVcdNetworkStateV0toV1(is, meta)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+// migrateVcdNetworkStateV0toV1 replaces the network name, which was used as
+// the ID, with the network HREF.
+func migrateVcdNetworkStateV0toV1(is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	if href := is.Attributes["href"]; href != "" {
+		is.ID = href
+	} else {
+		vcdClient := meta.(*VCDClient)
+		network, err := vcdClient.OrgVdc.FindVDCNetwork(is.ID)
+		if err != nil {
+			return is, fmt.Errorf("Error finding network %s: %#v", is.ID, err)
+		}
+		is.ID = network.OrgVDCNetwork.HREF
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
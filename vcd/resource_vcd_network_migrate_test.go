@@ -0,0 +1,67 @@
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestVcdNetworkMigrateState(t *testing.T) {
+	cases := map[string]struct {
+		StateVersion int
+		ID           string
+		Attributes   map[string]string
+		Expected     string
+	}{
+		"v0_1_href": {
+			StateVersion: 0,
+			ID:           "my-net",
+			Attributes: map[string]string{
+				"name": "my-net",
+				"href": "https://vcd.example.com/api/network/1111",
+			},
+			Expected: "https://vcd.example.com/api/network/1111",
+		},
+	}
+
+	for tn, tc := range cases {
+		is := &terraform.InstanceState{
+			ID:         tc.ID,
+			Attributes: tc.Attributes,
+		}
+		is, err := resourceVcdNetworkMigrateState(tc.StateVersion, is, nil)
+		if err != nil {
+			t.Fatalf("bad: %s, err: %#v", tn, err)
+		}
+
+		if is.ID != tc.Expected {
+			t.Fatalf("bad ID for %s: expected %q, got %q", tn, tc.Expected, is.ID)
+		}
+	}
+}
+
+func TestVcdNetworkMigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+
+	// should handle nil
+	is, err := resourceVcdNetworkMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	is, err = resourceVcdNetworkMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+
+	// should refuse unknown versions
+	_, err = resourceVcdNetworkMigrateState(1, &terraform.InstanceState{}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown schema version")
+	}
+}
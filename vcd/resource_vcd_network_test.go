@@ -0,0 +1,43 @@
+package vcd
+
+import (
+	"reflect"
+	"testing"
+
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func TestReplaceNetworkDhcpPools(t *testing.T) {
+	networkHREF := "https://vcd.example.com/api/network/1111"
+	adminHREF := "https://vcd.example.com/api/admin/network/1111"
+	otherHREF := "https://vcd.example.com/api/admin/network/2222"
+
+	own := &types.DhcpPoolService{Network: &types.Reference{HREF: adminHREF}, LowIPAddress: "10.1.0.100"}
+	other := &types.DhcpPoolService{Network: &types.Reference{HREF: otherHREF}, LowIPAddress: "10.2.0.100"}
+	newPool := &types.DhcpPoolService{Network: &types.Reference{HREF: networkHREF}, LowIPAddress: "10.1.0.150"}
+
+	cases := []struct {
+		name     string
+		service  *types.GatewayDhcpService
+		pools    []*types.DhcpPoolService
+		expected *types.GatewayDhcpService
+	}{
+		{"add to missing service", nil, []*types.DhcpPoolService{newPool},
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{newPool}}},
+		{"replace by admin HREF", &types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{own, other}}, []*types.DhcpPoolService{newPool},
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other, newPool}}},
+		{"remove", &types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other, own}}, nil,
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other}}},
+		{"remove last keeps the service state", &types.GatewayDhcpService{Pool: []*types.DhcpPoolService{own}}, nil,
+			&types.GatewayDhcpService{}},
+		{"other networks only", &types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other}}, nil,
+			&types.GatewayDhcpService{IsEnabled: true, Pool: []*types.DhcpPoolService{other}}},
+	}
+
+	for _, c := range cases {
+		actual := replaceNetworkDhcpPools(c.service, networkHREF, c.pools)
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %#v, got %#v", c.name, c.expected, actual)
+		}
+	}
+}
@@ -2,17 +2,22 @@ package vcd
 
 import (
 	"fmt"
+	"log"
 
-	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
+	types "github.com/vCloud/govcloudair/types/v56"
 )
 
 func resourceVcdSNAT() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVcdSNATCreate,
+		Update: resourceVcdSNATUpdate,
 		Delete: resourceVcdSNATDelete,
 		Read:   resourceVcdSNATRead,
 
+		SchemaVersion: 1,
+		MigrateState:  resourceVcdSNATMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"edge_gateway": &schema.Schema{
 				Type:     schema.TypeString,
@@ -23,101 +28,94 @@ func resourceVcdSNAT() *schema.Resource {
 			"external_ip": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"internal_ip": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
+			},
+
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
 			},
 		},
 	}
 }
 
 func resourceVcdSNATCreate(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	// Multiple VCD components need to run operations on the Edge Gateway, as
-	// the edge gatway will throw back an error if it is already performing an
-	// operation we must wait until we can aquire a lock on the client
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
-
-	// Creating a loop to offer further protection from the edge gateway erroring
-	// due to being busy eg another person is using another client so wouldn't be
-	// constrained by out lock. If the edge gateway reurns with a busy error, wait
-	// 3 seconds and then try again. Continue until a non-busy error or success
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	rule := &types.NatRule{
+		RuleType:  "SNAT",
+		IsEnabled: d.Get("enabled").(bool),
+		GatewayNatRule: &types.GatewayNatRule{
+			OriginalIP:     d.Get("internal_ip").(string),
+			OriginalPort:   "any",
+			TranslatedIP:   d.Get("external_ip").(string),
+			TranslatedPort: "any",
+			Protocol:       "tcp",
+		},
+	}
+
+	id, err := addNatMapping(d.Get("edge_gateway").(string), rule,
+		snatRuleMatcher(d.Get("internal_ip").(string), d.Get("external_ip").(string)), meta)
 	if err != nil {
-		return fmt.Errorf("Unable to find edge gateway: %#v", err)
+		return fmt.Errorf("Error adding SNAT rule: %#v", err)
 	}
 
-	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		task, err := edgeGateway.AddNATMapping("SNAT", d.Get("internal_ip").(string),
-			d.Get("external_ip").(string),
-			"any")
-		if err != nil {
-			return resource.RetryableError(fmt.Errorf("Error setting SNAT rules: %#v", err))
-		}
-		return resource.RetryableError(task.WaitTaskCompletion())
-	})
+	d.SetId(id)
+
+	return resourceVcdSNATRead(d, meta)
+}
+
+func resourceVcdSNATUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := updateNatRule(d.Get("edge_gateway").(string), d.Id(), func(rule *types.NatRule) {
+		rule.IsEnabled = d.Get("enabled").(bool)
+		rule.GatewayNatRule.OriginalIP = d.Get("internal_ip").(string)
+		rule.GatewayNatRule.TranslatedIP = d.Get("external_ip").(string)
+	}, meta)
 	if err != nil {
-		return err
+		return fmt.Errorf("Error updating SNAT rule %s: %#v", d.Id(), err)
 	}
 
-	d.SetId(d.Get("internal_ip").(string))
-	return nil
+	return resourceVcdSNATRead(d, meta)
+}
+
+// snatRuleMatcher matches SNAT rules translating internalIP to externalIP.
+func snatRuleMatcher(internalIP, externalIP string) func(*types.NatRule) bool {
+	return func(r *types.NatRule) bool {
+		return r.RuleType == "SNAT" &&
+			r.GatewayNatRule != nil &&
+			r.GatewayNatRule.OriginalIP == internalIP &&
+			r.GatewayNatRule.TranslatedIP == externalIP
+	}
 }
 
 func resourceVcdSNATRead(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	e, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
+	e, err := getEdgeGateway(d.Get("edge_gateway").(string), meta)
 
 	if err != nil {
 		return fmt.Errorf("Unable to find edge gateway: %#v", err)
 	}
 
-	var found bool
-
-	for _, r := range e.EdgeGateway.Configuration.EdgeGatewayServiceConfiguration.NatService.NatRule {
-		if r.RuleType == "SNAT" &&
-			r.GatewayNatRule.OriginalIP == d.Id() {
-			found = true
-			d.Set("external_ip", r.GatewayNatRule.TranslatedIP)
-		}
-	}
-
-	if !found {
+	r := findNatRule(edgeGatewayNatRules(e), d.Id())
+	if r == nil || r.RuleType != "SNAT" || r.GatewayNatRule == nil {
+		log.Printf("[DEBUG] Unable to find SNAT rule %s. Removing from tfstate", d.Id())
 		d.SetId("")
+		return nil
 	}
 
+	d.Set("internal_ip", r.GatewayNatRule.OriginalIP)
+	d.Set("external_ip", r.GatewayNatRule.TranslatedIP)
+	d.Set("enabled", r.IsEnabled)
+
 	return nil
 }
 
 func resourceVcdSNATDelete(d *schema.ResourceData, meta interface{}) error {
-	vcdClient := meta.(*VCDClient)
-	// Multiple VCD components need to run operations on the Edge Gateway, as
-	// the edge gatway will throw back an error if it is already performing an
-	// operation we must wait until we can aquire a lock on the client
-	vcdClient.Mutex.Lock()
-	defer vcdClient.Mutex.Unlock()
-
-	edgeGateway, err := vcdClient.OrgVdc.FindEdgeGateway(d.Get("edge_gateway").(string))
-	if err != nil {
-		return fmt.Errorf("Unable to find edge gateway: %#v", err)
-	}
-
-	err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		task, err := edgeGateway.RemoveNATMapping("SNAT", d.Get("internal_ip").(string),
-			d.Get("external_ip").(string),
-			"")
-		if err != nil {
-			return resource.RetryableError(fmt.Errorf("Error setting SNAT rules: %#v", err))
-		}
-		return resource.RetryableError(task.WaitTaskCompletion())
-	})
+	err := removeNatRule(d.Get("edge_gateway").(string), d.Id(), meta)
 	if err != nil {
-		return err
+		return fmt.Errorf("Error removing SNAT rule %s: %#v", d.Id(), err)
 	}
 
 	return nil
@@ -0,0 +1,46 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func resourceVcdSNATMigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found vcd_snat State v0; migrating to v1")
+		return migrateVcdSNATStateV0toV1(is, meta)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+// migrateVcdSNATStateV0toV1 replaces the internal IP, which was used as the
+// ID, with the Id of the NAT rule.
+func migrateVcdSNATStateV0toV1(is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	edgeGateway, err := getEdgeGateway(is.Attributes["edge_gateway"], meta)
+	if err != nil {
+		return is, fmt.Errorf("Error finding edge gateway %s: %#v", is.Attributes["edge_gateway"], err)
+	}
+
+	id := findNatRuleID(edgeGatewayNatRules(edgeGateway),
+		snatRuleMatcher(is.Attributes["internal_ip"], is.Attributes["external_ip"]))
+	if id == "" {
+		// Read removes the rule from state, so it gets created again
+		log.Printf("[DEBUG] Unable to find SNAT rule for %s; keeping its ID", is.ID)
+	} else {
+		is.ID = id
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
@@ -0,0 +1,56 @@
+package vcd
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func TestVcdSNATMigrateFindRule(t *testing.T) {
+	dnat := &types.NatRule{ID: "65537", RuleType: "DNAT", GatewayNatRule: &types.GatewayNatRule{OriginalIP: "10.10.102.50", TranslatedIP: "203.0.113.10"}}
+	snat := &types.NatRule{ID: "65538", RuleType: "SNAT", GatewayNatRule: &types.GatewayNatRule{OriginalIP: "10.10.102.50", TranslatedIP: "203.0.113.10"}}
+	rules := []*types.NatRule{dnat, snat}
+
+	cases := map[string]struct {
+		InternalIP string
+		ExternalIP string
+		Expected   string
+	}{
+		"found":     {"10.10.102.50", "203.0.113.10", "65538"},
+		"not_found": {"10.10.102.51", "203.0.113.10", ""},
+	}
+
+	for tn, tc := range cases {
+		id := findNatRuleID(rules, snatRuleMatcher(tc.InternalIP, tc.ExternalIP))
+		if id != tc.Expected {
+			t.Fatalf("bad ID for %s: expected %q, got %q", tn, tc.Expected, id)
+		}
+	}
+}
+
+func TestVcdSNATMigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+
+	// should handle nil
+	is, err := resourceVcdSNATMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	is, err = resourceVcdSNATMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+
+	// should refuse unknown versions
+	_, err = resourceVcdSNATMigrateState(1, &terraform.InstanceState{}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown schema version")
+	}
+}
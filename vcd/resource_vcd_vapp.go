@@ -6,7 +6,9 @@ import (
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/vCloud/govcloudair"
+	types "github.com/vCloud/govcloudair/types/v56"
 )
 
 func resourceVcdVApp() *schema.Resource {
@@ -16,6 +18,9 @@ func resourceVcdVApp() *schema.Resource {
 		Read:   resourceVcdVAppRead,
 		Delete: resourceVcdVAppDelete,
 
+		SchemaVersion: 1,
+		MigrateState:  resourceVcdVAppMigrateState,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -42,6 +47,33 @@ func resourceVcdVApp() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"power_on": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"deployment_lease_seconds": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"force_customization": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"undeploy_action": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  types.UndeployPowerActionDefault,
+				ValidateFunc: validation.StringInSlice([]string{
+					types.UndeployPowerActionDefault,
+					types.UndeployPowerActionPowerOff,
+					types.UndeployPowerActionSuspend,
+					types.UndeployPowerActionShutdown,
+					types.UndeployPowerActionForce,
+				}, false),
+			},
 		},
 	}
 }
@@ -63,11 +95,25 @@ func resourceVcdVAppCreate(d *schema.ResourceData, meta interface{}) error {
 		vapp = vcdClient.NewVApp(&vcdClient.Client)
 
 		err = retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
-			return vapp.ComposeVApp(d.Get("name").(string), d.Get("description").(string), networks)
+			return vapp.ComposeVApp(d.Get("name").(string), d.Get("description").(string), nil)
 		})
 		if err != nil {
 			return err
 		}
+
+		// The networks are set afterwards, govcloudair's types can't hold
+		// all of their features
+		if len(networks) > 0 {
+			err = updateVAppNetworkConfigurations(vapp.VApp.HREF, func(existing []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error) {
+				for _, network := range networks {
+					existing = replaceVAppNetworkConfiguration(existing, network.NetworkName, network)
+				}
+				return existing, nil
+			}, meta)
+			if err != nil {
+				return fmt.Errorf("Error setting network: %#v", err)
+			}
+		}
 	}
 
 	log.Printf("[DEBUG] vApp created with href:  %s", vapp.VApp.HREF)
@@ -89,11 +135,24 @@ func resourceVcdVAppCreate(d *schema.ResourceData, meta interface{}) error {
 	// This should be HREF, but FindVAppByHREF is buggy
 	d.SetId(vapp.VApp.HREF)
 
+	if powerOn, ok := d.GetOkExists("power_on"); ok && powerOn.(bool) {
+		log.Printf("[DEBUG] (%s) Deploying and powering on vApp after Create", vapp.VApp.Name)
+		err = retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+			return deployVApp(vapp, d, meta)
+		})
+		if err != nil {
+			return fmt.Errorf("Error deploying vApp: %#v", err)
+		}
+	}
+
 	return nil
 }
 
 func resourceVcdVAppUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(d.Id())
+	defer vcdClient.locks.unlock(d.Id())
+
 	log.Printf("[TRACE] Updating state from VCD")
 	err := vcdClient.OrgVdc.Refresh()
 	if err != nil {
@@ -134,16 +193,50 @@ func resourceVcdVAppUpdate(d *schema.ResourceData, meta interface{}) error {
 			return err
 		}
 
-		err = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-			task, err := vapp.SetNetworkConfigurations(networks)
-			if err != nil {
-				return resource.NonRetryableError(fmt.Errorf("Error setting network: %#v", err))
+		// Only the networks listed in this resource are touched, the ones
+		// managed by vcd_vapp_network and vcd_vapp_org_network are kept
+		removed := removedVAppNetworkNames(d.GetChange("organization_network"))
+		removed = append(removed, removedVAppNetworkNames(d.GetChange("vapp_network"))...)
+
+		err = updateVAppNetworkConfigurationsUnlocked(d.Id(), func(existing []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error) {
+			for _, name := range removed {
+				existing = replaceVAppNetworkConfiguration(existing, name, nil)
+			}
+			for _, network := range networks {
+				existing = replaceVAppNetworkConfiguration(existing, network.NetworkName, network)
 			}
+			return existing, nil
+		}, meta)
+		if err != nil {
+			return fmt.Errorf("Error setting network: %#v", err)
+		}
+	}
 
-			return resource.RetryableError(task.WaitTaskCompletion())
-		})
+	// Update power state, deploying takes the lease along
+	if d.HasChange("power_on") {
+		if d.Get("power_on").(bool) {
+			log.Printf("[DEBUG] (%s) Deploying and powering on vApp", vapp.VApp.Name)
+			err = retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+				return deployVApp(vapp, d, meta)
+			})
+		} else {
+			log.Printf("[DEBUG] (%s) Undeploying vApp", vapp.VApp.Name)
+			err = retryCallWithBusyEntityErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
+				return undeployVApp(vapp, d.Get("undeploy_action").(string), meta)
+			})
+		}
 		if err != nil {
-			return fmt.Errorf("Error completing task: %#v", err)
+			return fmt.Errorf("Error changing vApp power state: %#v", err)
+		}
+	}
+
+	// Deploying an already deployed vApp fails, so the lease of a deployed
+	// vApp is changed on its own
+	if !d.HasChange("power_on") && d.Get("power_on").(bool) && d.HasChange("deployment_lease_seconds") {
+		log.Printf("[DEBUG] (%s) Changing deployment lease", vapp.VApp.Name)
+		err = setVAppDeploymentLease(vapp.VApp.HREF, d.Get("deployment_lease_seconds").(int), meta)
+		if err != nil {
+			return fmt.Errorf("Error changing vApp deployment lease: %#v", err)
 		}
 	}
 
@@ -177,6 +270,9 @@ func resourceVcdVAppRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceVcdVAppDelete(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(d.Id())
+	defer vcdClient.locks.unlock(d.Id())
+
 	log.Printf("[TRACE] Updating state from VCD")
 	err := vcdClient.OrgVdc.Refresh()
 	if err != nil {
@@ -196,7 +292,7 @@ func resourceVcdVAppDelete(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	_ = retryCall(vcdClient.MaxRetryTimeout, func() *resource.RetryError {
-		task, err := vapp.Undeploy()
+		task, err := undeployVApp(vapp, d.Get("undeploy_action").(string), meta)
 		if err != nil {
 			return resource.NonRetryableError(fmt.Errorf("Error undeploying: %#v", err))
 		}
@@ -0,0 +1,65 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func resourceVcdVAppMigrateState(v int, is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	switch v {
+	case 0:
+		log.Println("[INFO] Found vcd_vapp State v0; migrating to v1")
+		return migrateVcdVAppStateV0toV1(is)
+	default:
+		return is, fmt.Errorf("Unexpected schema version: %d", v)
+	}
+}
+
+// migrateVcdVAppStateV0toV1 moves start and end of each vapp_network into a
+// static_ip_pool block, and dhcp_start and dhcp_end into a dhcp block when
+// the former dhcp flag was set.
+func migrateVcdVAppStateV0toV1(is *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty InstanceState; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] Attributes before migration: %#v", is.Attributes)
+
+	count, _ := strconv.Atoi(is.Attributes["vapp_network.#"])
+	for i := 0; i < count; i++ {
+		prefix := fmt.Sprintf("vapp_network.%d.", i)
+
+		start, end := is.Attributes[prefix+"start"], is.Attributes[prefix+"end"]
+		delete(is.Attributes, prefix+"start")
+		delete(is.Attributes, prefix+"end")
+		if start != "" && end != "" {
+			is.Attributes[prefix+"static_ip_pool.#"] = "1"
+			is.Attributes[prefix+"static_ip_pool.0.start_address"] = start
+			is.Attributes[prefix+"static_ip_pool.0.end_address"] = end
+		} else {
+			is.Attributes[prefix+"static_ip_pool.#"] = "0"
+		}
+
+		dhcp := is.Attributes[prefix+"dhcp"]
+		dhcpStart, dhcpEnd := is.Attributes[prefix+"dhcp_start"], is.Attributes[prefix+"dhcp_end"]
+		delete(is.Attributes, prefix+"dhcp")
+		delete(is.Attributes, prefix+"dhcp_start")
+		delete(is.Attributes, prefix+"dhcp_end")
+		if dhcp == "true" && dhcpStart != "" && dhcpEnd != "" {
+			is.Attributes[prefix+"dhcp.#"] = "1"
+			is.Attributes[prefix+"dhcp.0.start_address"] = dhcpStart
+			is.Attributes[prefix+"dhcp.0.end_address"] = dhcpEnd
+			is.Attributes[prefix+"dhcp.0.default_lease_time"] = "3600"
+			is.Attributes[prefix+"dhcp.0.max_lease_time"] = "7200"
+		} else {
+			is.Attributes[prefix+"dhcp.#"] = "0"
+		}
+	}
+
+	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
+	return is, nil
+}
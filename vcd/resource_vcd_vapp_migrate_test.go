@@ -0,0 +1,119 @@
+package vcd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestVcdVAppMigrateState(t *testing.T) {
+	cases := map[string]struct {
+		StateVersion int
+		Attributes   map[string]string
+		Expected     map[string]string
+	}{
+		"v0_1_dhcp": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"name":                      "web",
+				"vapp_network.#":            "1",
+				"vapp_network.0.name":       "internal",
+				"vapp_network.0.start":      "192.168.2.100",
+				"vapp_network.0.end":        "192.168.2.199",
+				"vapp_network.0.dhcp":       "true",
+				"vapp_network.0.dhcp_start": "192.168.2.200",
+				"vapp_network.0.dhcp_end":   "192.168.2.249",
+				"organization_network.#":    "1",
+				"organization_network.0":    "services",
+			},
+			Expected: map[string]string{
+				"name":                            "web",
+				"vapp_network.#":                  "1",
+				"vapp_network.0.name":             "internal",
+				"vapp_network.0.static_ip_pool.#": "1",
+				"vapp_network.0.static_ip_pool.0.start_address": "192.168.2.100",
+				"vapp_network.0.static_ip_pool.0.end_address":   "192.168.2.199",
+				"vapp_network.0.dhcp.#":                         "1",
+				"vapp_network.0.dhcp.0.start_address":           "192.168.2.200",
+				"vapp_network.0.dhcp.0.end_address":             "192.168.2.249",
+				"vapp_network.0.dhcp.0.default_lease_time":      "3600",
+				"vapp_network.0.dhcp.0.max_lease_time":          "7200",
+				"organization_network.#":                        "1",
+				"organization_network.0":                        "services",
+			},
+		},
+		"v0_1_no_dhcp": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"name":                      "web",
+				"vapp_network.#":            "1",
+				"vapp_network.0.name":       "internal",
+				"vapp_network.0.start":      "192.168.2.100",
+				"vapp_network.0.end":        "192.168.2.199",
+				"vapp_network.0.dhcp":       "false",
+				"vapp_network.0.dhcp_start": "",
+				"vapp_network.0.dhcp_end":   "",
+			},
+			Expected: map[string]string{
+				"name":                            "web",
+				"vapp_network.#":                  "1",
+				"vapp_network.0.name":             "internal",
+				"vapp_network.0.static_ip_pool.#": "1",
+				"vapp_network.0.static_ip_pool.0.start_address": "192.168.2.100",
+				"vapp_network.0.static_ip_pool.0.end_address":   "192.168.2.199",
+				"vapp_network.0.dhcp.#":                         "0",
+			},
+		},
+		"v0_1_no_networks": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"name": "web",
+			},
+			Expected: map[string]string{
+				"name": "web",
+			},
+		},
+	}
+
+	for tn, tc := range cases {
+		is := &terraform.InstanceState{
+			ID:         "https://vcd.example.com/api/vApp/vapp-1234",
+			Attributes: tc.Attributes,
+		}
+		is, err := resourceVcdVAppMigrateState(tc.StateVersion, is, nil)
+		if err != nil {
+			t.Fatalf("bad: %s, err: %#v", tn, err)
+		}
+
+		if !reflect.DeepEqual(is.Attributes, tc.Expected) {
+			t.Fatalf("bad attributes for %s: expected %#v, got %#v", tn, tc.Expected, is.Attributes)
+		}
+	}
+}
+
+func TestVcdVAppMigrateState_empty(t *testing.T) {
+	var is *terraform.InstanceState
+
+	// should handle nil
+	is, err := resourceVcdVAppMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+	if is != nil {
+		t.Fatalf("expected nil instancestate, got: %#v", is)
+	}
+
+	// should handle non-nil but empty
+	is = &terraform.InstanceState{}
+	is, err = resourceVcdVAppMigrateState(0, is, nil)
+	if err != nil {
+		t.Fatalf("err: %#v", err)
+	}
+
+	// should refuse unknown versions
+	_, err = resourceVcdVAppMigrateState(1, &terraform.InstanceState{}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown schema version")
+	}
+}
@@ -0,0 +1,157 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdVAppNetwork() *schema.Resource {
+	s := VAppNetworkSubresourceSchema()
+	s["name"].ForceNew = true
+	s["vapp_href"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+		ForceNew: true,
+	}
+
+	return &schema.Resource{
+		Create: resourceVcdVAppNetworkCreate,
+		Update: resourceVcdVAppNetworkUpdate,
+		Read:   resourceVcdVAppNetworkRead,
+		Delete: resourceVcdVAppNetworkDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVcdVAppNetworkImport,
+		},
+
+		Schema: s,
+	}
+}
+
+// vAppNetworkSubresourceFromResourceData copies the vApp network attributes of
+// d into a VAppNetworkSubresource, so the vcd_vapp helpers can be reused.
+func vAppNetworkSubresourceFromResourceData(d *schema.ResourceData) *VAppNetworkSubresource {
+	data := make(map[string]interface{})
+	for key := range VAppNetworkSubresourceSchema() {
+		data[key] = d.Get(key)
+	}
+
+	return NewVAppNetworkSubresource(data, nil)
+}
+
+func resourceVcdVAppNetworkCreate(d *schema.ResourceData, meta interface{}) error {
+	vappHREF := d.Get("vapp_href").(string)
+	name := d.Get("name").(string)
+
+	err := updateVAppNetworkConfigurations(vappHREF, func(networks []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error) {
+		for _, network := range networks {
+			if network.NetworkName == name {
+				return nil, fmt.Errorf("vApp %s already has a network named %s", vappHREF, name)
+			}
+		}
+
+		network, err := expandVAppNetworkConfiguration(vAppNetworkSubresourceFromResourceData(d), vms, meta)
+		if err != nil {
+			return nil, err
+		}
+
+		return replaceVAppNetworkConfiguration(networks, name, network), nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding vApp network %s: %#v", name, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", vappHREF, name))
+
+	return resourceVcdVAppNetworkRead(d, meta)
+}
+
+func resourceVcdVAppNetworkUpdate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	err := updateVAppNetworkConfigurations(d.Get("vapp_href").(string), func(networks []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error) {
+		network, err := expandVAppNetworkConfiguration(vAppNetworkSubresourceFromResourceData(d), vms, meta)
+		if err != nil {
+			return nil, err
+		}
+
+		return replaceVAppNetworkConfiguration(networks, name, network), nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error updating vApp network %s: %#v", name, err)
+	}
+
+	return resourceVcdVAppNetworkRead(d, meta)
+}
+
+func resourceVcdVAppNetworkRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	// GetVAppByHREF doesn't keep the vCD error, which tells a removed vApp
+	// from a failed request
+	vapp := &types.VApp{}
+	err := getEntity(d.Get("vapp_href").(string), vapp, &vcdClient.Client)
+	if err != nil {
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Unable to find vapp. Removing from tfstate")
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading vApp: %#v", err)
+	}
+
+	section, err := getVAppNetworkConfigSection(vapp.HREF, meta)
+	if err != nil {
+		return err
+	}
+
+	vAppNetwork := findVAppNetworkConfiguration(section, d.Get("name").(string))
+	if vAppNetwork == nil {
+		log.Printf("[DEBUG] Unable to find vApp network %s. Removing from tfstate", d.Get("name").(string))
+		d.SetId("")
+		return nil
+	}
+
+	var vms []*types.VM
+	if vapp.Children != nil {
+		vms = vapp.Children.VM
+	}
+
+	vAppNetworkResource := vAppNetworkSubresourceFromResourceData(d)
+	flattenVAppNetworkConfiguration(vAppNetworkResource, vAppNetwork, vms)
+
+	for key, value := range vAppNetworkResource.Data() {
+		d.Set(key, value)
+	}
+
+	return nil
+}
+
+func resourceVcdVAppNetworkDelete(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	err := updateVAppNetworkConfigurations(d.Get("vapp_href").(string), func(networks []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error) {
+		return replaceVAppNetworkConfiguration(networks, name, nil), nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing vApp network %s: %#v", name, err)
+	}
+
+	return nil
+}
+
+// resourceVcdVAppNetworkImport imports a vApp network given as
+// vapp-href:network-name, the same format as the resource ID.
+func resourceVcdVAppNetworkImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	vappHREF, name, err := splitVAppNetworkID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("vapp_href", vappHREF)
+	d.Set("name", name)
+
+	return []*schema.ResourceData{d}, nil
+}
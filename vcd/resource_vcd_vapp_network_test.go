@@ -0,0 +1,93 @@
+package vcd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// testAccCheckVcdVAppNetworkGone checks that the vApp at vappHREF, unless it
+// is gone itself, has no network named name.
+func testAccCheckVcdVAppNetworkGone(vappHREF, name string) error {
+	conn := testAccProvider.Meta().(*VCDClient)
+
+	if _, err := conn.OrgVdc.GetVAppByHREF(vappHREF); err != nil {
+		return nil
+	}
+
+	section, err := getVAppNetworkConfigSection(vappHREF, testAccProvider.Meta())
+	if err != nil {
+		return err
+	}
+
+	if findVAppNetworkConfiguration(section, name) != nil {
+		return fmt.Errorf("vApp network %s still exists", name)
+	}
+
+	return nil
+}
+
+func testAccCheckVcdVAppNetworkDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_vapp_network" {
+			continue
+		}
+
+		if err := testAccCheckVcdVAppNetworkGone(rs.Primary.Attributes["vapp_href"], rs.Primary.Attributes["name"]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdVAppNetwork_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdVAppNetworkDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdVAppNetwork_basic, "8.8.4.4"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_vapp_network.test", "name", "terraform-acc-vapp-net"),
+					resource.TestCheckResourceAttr(
+						"vcd_vapp_network.test", "gateway", "192.168.12.1"),
+					resource.TestCheckResourceAttr(
+						"vcd_vapp_network.test", "dns2", "8.8.4.4"),
+				),
+			},
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdVAppNetwork_basic, "1.1.1.1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_vapp_network.test", "dns2", "1.1.1.1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdVAppNetwork_basic = `
+resource "vcd_vapp" "test" {
+  name = "terraform-acc-vapp-network"
+}
+
+resource "vcd_vapp_network" "test" {
+  vapp_href = "${vcd_vapp.test.id}"
+  name      = "terraform-acc-vapp-net"
+  gateway   = "192.168.12.1"
+  netmask   = "255.255.255.0"
+  dns1      = "8.8.8.8"
+  dns2      = "%s"
+  nat       = false
+
+  static_ip_pool {
+    start_address = "192.168.12.100"
+    end_address   = "192.168.12.199"
+  }
+}
+`
@@ -0,0 +1,120 @@
+package vcd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+func resourceVcdVAppOrgNetwork() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVcdVAppOrgNetworkCreate,
+		Read:   resourceVcdVAppOrgNetworkRead,
+		Delete: resourceVcdVAppOrgNetworkDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVcdVAppOrgNetworkImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"vapp_href": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"org_network": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceVcdVAppOrgNetworkCreate(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+	vappHREF := d.Get("vapp_href").(string)
+	name := d.Get("org_network").(string)
+
+	orgnetwork, err := vcdClient.OrgVdc.FindVDCNetwork(name)
+	if err != nil {
+		return fmt.Errorf("Error finding vdc org network: %s, %#v", name, err)
+	}
+
+	err = updateVAppNetworkConfigurations(vappHREF, func(networks []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error) {
+		for _, network := range networks {
+			if network.NetworkName == name {
+				return nil, fmt.Errorf("vApp %s already has a network named %s", vappHREF, name)
+			}
+		}
+
+		return replaceVAppNetworkConfiguration(networks, name, orgVDCNetworkToNetworkConfiguration(orgnetwork.OrgVDCNetwork)), nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error adding organization network %s to vApp: %#v", name, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", vappHREF, name))
+
+	return resourceVcdVAppOrgNetworkRead(d, meta)
+}
+
+func resourceVcdVAppOrgNetworkRead(d *schema.ResourceData, meta interface{}) error {
+	vcdClient := meta.(*VCDClient)
+
+	// GetVAppByHREF doesn't keep the vCD error, which tells a removed vApp
+	// from a failed request
+	vapp := &types.VApp{}
+	err := getEntity(d.Get("vapp_href").(string), vapp, &vcdClient.Client)
+	if err != nil {
+		if isNotFoundError(err) {
+			log.Printf("[DEBUG] Unable to find vapp. Removing from tfstate")
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading vApp: %#v", err)
+	}
+
+	section, err := getVAppNetworkConfigSection(vapp.HREF, meta)
+	if err != nil {
+		return err
+	}
+
+	vAppNetwork := findVAppNetworkConfiguration(section, d.Get("org_network").(string))
+	if vAppNetwork == nil || vAppNetwork.Configuration == nil ||
+		vAppNetwork.Configuration.FenceMode != types.FenceModeBridged {
+		log.Printf("[DEBUG] Unable to find organization network %s in vApp. Removing from tfstate", d.Get("org_network").(string))
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+func resourceVcdVAppOrgNetworkDelete(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("org_network").(string)
+
+	err := updateVAppNetworkConfigurations(d.Get("vapp_href").(string), func(networks []*VAppNetworkConfiguration, vms []*types.VM) ([]*VAppNetworkConfiguration, error) {
+		return replaceVAppNetworkConfiguration(networks, name, nil), nil
+	}, meta)
+	if err != nil {
+		return fmt.Errorf("Error removing organization network %s from vApp: %#v", name, err)
+	}
+
+	return nil
+}
+
+// resourceVcdVAppOrgNetworkImport imports an organization network of a vApp
+// given as vapp-href:network-name, the same format as the resource ID.
+func resourceVcdVAppOrgNetworkImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	vappHREF, name, err := splitVAppNetworkID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("vapp_href", vappHREF)
+	d.Set("org_network", name)
+
+	return []*schema.ResourceData{d}, nil
+}
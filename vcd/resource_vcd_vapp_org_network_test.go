@@ -0,0 +1,67 @@
+package vcd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func testAccCheckVcdVAppOrgNetworkDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "vcd_vapp_org_network" {
+			continue
+		}
+
+		if err := testAccCheckVcdVAppNetworkGone(rs.Primary.Attributes["vapp_href"], rs.Primary.Attributes["org_network"]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestAccVcdVAppOrgNetwork_Basic(t *testing.T) {
+	edgeGateway := os.Getenv("VCD_EDGE_GATEWAY")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVcdVAppOrgNetworkDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: fmt.Sprintf(testAccCheckVcdVAppOrgNetwork_basic, edgeGateway),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"vcd_vapp_org_network.test", "org_network", "terraform-acc-vapp-org-net"),
+					resource.TestCheckResourceAttrPair(
+						"vcd_vapp_org_network.test", "vapp_href", "vcd_vapp.test", "id"),
+				),
+			},
+		},
+	})
+}
+
+const testAccCheckVcdVAppOrgNetwork_basic = `
+resource "vcd_network" "test" {
+  name         = "terraform-acc-vapp-org-net"
+  edge_gateway = "%s"
+  gateway      = "10.10.104.1"
+
+  static_ip_pool {
+    start_address = "10.10.104.2"
+    end_address   = "10.10.104.50"
+  }
+}
+
+resource "vcd_vapp" "test" {
+  name = "terraform-acc-vapp-org-network"
+}
+
+resource "vcd_vapp_org_network" "test" {
+  vapp_href   = "${vcd_vapp.test.id}"
+  org_network = "${vcd_network.test.name}"
+}
+`
@@ -115,11 +115,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -128,13 +129,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -156,11 +160,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -169,13 +174,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -197,11 +205,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -210,13 +219,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -320,11 +332,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -333,13 +346,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -361,13 +377,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
   vapp_network {
      name = "test"
@@ -376,11 +395,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
 
 }
@@ -410,11 +430,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -423,13 +444,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/vCloud/govcloudair"
 	types "github.com/vCloud/govcloudair/types/v56"
 )
@@ -86,12 +87,49 @@ func resourceVcdVM() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"startup_order": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"start_action": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "powerOn",
+				ValidateFunc: validation.StringInSlice([]string{
+					"powerOn",
+					"none",
+				}, false),
+			},
+			"start_delay": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"stop_action": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "powerOff",
+				ValidateFunc: validation.StringInSlice([]string{
+					"powerOff",
+					"guestShutdown",
+				}, false),
+			},
+			"stop_delay": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
 		},
 	}
 }
 
 func resourceVcdVMCreate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
+	// VMs are added, reconfigured and removed through their vApp
+	vcdClient.locks.lock(d.Get("vapp_href").(string))
+	defer vcdClient.locks.unlock(d.Get("vapp_href").(string))
+
 	log.Printf("[TRACE] Updating state from VCD")
 	err := vcdClient.OrgVdc.Refresh()
 	if err != nil {
@@ -159,6 +197,13 @@ func resourceVcdVMCreate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	log.Printf("[TRACE] (%s) Updating startup section of vApp", d.Get("name").(string))
+	err = configureVMStartup(d, meta)
+
+	if err != nil {
+		return err
+	}
+
 	err = readVM(d, meta)
 
 	if err != nil {
@@ -193,6 +238,8 @@ func resourceVcdVMCreate(d *schema.ResourceData, meta interface{}) error {
 
 func resourceVcdVMUpdate(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(d.Get("vapp_href").(string))
+	defer vcdClient.locks.unlock(d.Get("vapp_href").(string))
 
 	// Get VM object from VCD
 	vm, err := vcdClient.OrgVdc.GetVMByHREF(d.Get("href").(string))
@@ -253,6 +300,13 @@ func resourceVcdVMUpdate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	log.Printf("[TRACE] (%s) Updating startup section of vApp", d.Get("name").(string))
+	err = configureVMStartup(d, meta)
+
+	if err != nil {
+		return err
+	}
+
 	err = readVM(d, meta)
 
 	if err != nil {
@@ -297,6 +351,9 @@ func resourceVcdVMRead(d *schema.ResourceData, meta interface{}) error {
 
 func resourceVcdVMDelete(d *schema.ResourceData, meta interface{}) error {
 	vcdClient := meta.(*VCDClient)
+	vcdClient.locks.lock(d.Get("vapp_href").(string))
+	defer vcdClient.locks.unlock(d.Get("vapp_href").(string))
+
 	log.Printf("[TRACE] Updating state from VCD")
 	err := vcdClient.OrgVdc.Refresh()
 	if err != nil {
@@ -348,6 +405,13 @@ func resourceVcdVMDelete(d *schema.ResourceData, meta interface{}) error {
 	// 	return err
 	// }
 
+	// Removed first, so the VM is left in place if this fails
+	log.Printf("[TRACE] (%s) Removing VM from the startup section of vApp", d.Get("name").(string))
+	err = removeVMStartup(d.Get("vapp_href").(string), vm.VM.Name, meta)
+	if err != nil {
+		return err
+	}
+
 	log.Printf("[TRACE] (%s) Sending remove request to VCD", d.Get("name").(string))
 	err = retryCallWithVAppErrorHandling(vcdClient.MaxRetryTimeout, func() (govcloudair.Task, error) {
 		return vapp.RemoveVMs([]*types.VM{vm.VM})
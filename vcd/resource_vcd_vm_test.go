@@ -172,11 +172,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -185,13 +186,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -245,11 +249,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -258,13 +263,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -323,11 +331,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -336,13 +345,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }
@@ -618,11 +630,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -631,13 +644,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }`
@@ -1019,11 +1035,12 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.2.100"
-     end = "192.168.2.199"
+     static_ip_pool {
+       start_address = "192.168.2.100"
+       end_address = "192.168.2.199"
+     }
      nat = false
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = false
   }
   vapp_network {
      name = "test2"
@@ -1032,13 +1049,16 @@ resource "vcd_vapp" "test-vapp" {
      netmask = "255.255.255.0"
      dns1 = "8.8.8.8"
      dns2 = "8.8.4.4"
-     start = "192.168.3.100"
-     end = "192.168.3.199"
+     static_ip_pool {
+       start_address = "192.168.3.100"
+       end_address = "192.168.3.199"
+     }
      nat = true
      parent = "FCI-IRT_ISN6_ORG-SRV"
-     dhcp = true
-     dhcp_start = "192.168.3.200"
-     dhcp_end = "192.168.3.249"
+     dhcp {
+       start_address = "192.168.3.200"
+       end_address = "192.168.3.249"
+     }
   }
 
 }`
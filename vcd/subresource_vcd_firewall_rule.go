@@ -0,0 +1,134 @@
+package vcd
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// FirewallRuleSubresourceSchema is the schema of a firewall rule, shared by
+// vcd_firewall_rule and the rules of vcd_firewall_rules.
+func FirewallRuleSubresourceSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"description": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+
+		"enabled": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+
+		"policy": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+
+		"protocol": {
+			Type:             schema.TypeString,
+			Required:         true,
+			DiffSuppressFunc: suppressCaseDifferences,
+			ValidateFunc: validation.StringInSlice([]string{
+				"tcp",
+				"udp",
+				"tcp+udp",
+				"icmp",
+				"any",
+				"other",
+			}, true),
+		},
+
+		// Name of the protocol when protocol is other
+		"other_protocol": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"icmp_sub_type": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+
+		"destination_port": {
+			Type:             schema.TypeString,
+			Required:         true,
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+
+		"destination_ip": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+
+		"destination_vm": firewallRuleVMSelectionSchema(),
+
+		"source_port": {
+			Type:             schema.TypeString,
+			Required:         true,
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+
+		"source_ip": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+
+		"source_vm": firewallRuleVMSelectionSchema(),
+
+		"direction": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+			ValidateFunc: validation.StringInSlice([]string{
+				"in",
+				"out",
+			}, false),
+		},
+
+		"match_on_translate": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+
+		"logging": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+	}
+}
+
+func firewallRuleVMSelectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"vapp_scoped_vm_id": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"vm_nic_id": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  0,
+				},
+				"ip_type": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "assigned",
+					ValidateFunc: validation.StringInSlice([]string{
+						"assigned",
+						"NAT",
+					}, false),
+				},
+			},
+		},
+	}
+}
@@ -2,6 +2,7 @@ package vcd
 
 import (
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 )
 
 func VAppNetworkSubresourceSchema() map[string]*schema.Schema {
@@ -34,15 +35,33 @@ func VAppNetworkSubresourceSchema() map[string]*schema.Schema {
 			Type:     schema.TypeString,
 			Required: true,
 		},
-		"start": {
-			Type:         schema.TypeString,
-			Required:     true,
-			ValidateFunc: ValidateIPv4(),
+		"static_ip_pool": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"start_address": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: ValidateIPv4(),
+					},
+					"end_address": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: ValidateIPv4(),
+					},
+				},
+			},
 		},
-		"end": {
-			Type:         schema.TypeString,
-			Required:     true,
-			ValidateFunc: ValidateIPv4(),
+		"guest_vlan_allowed": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+		"retain_ip_mac_resources": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
 		},
 
 		"nat": {
@@ -54,21 +73,223 @@ func VAppNetworkSubresourceSchema() map[string]*schema.Schema {
 			Required: true,
 		},
 		"dhcp": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"start_address": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: ValidateIPv4(),
+					},
+					"end_address": {
+						Type:         schema.TypeString,
+						Required:     true,
+						ValidateFunc: ValidateIPv4(),
+					},
+					"default_lease_time": {
+						Type:     schema.TypeInt,
+						Optional: true,
+						Default:  3600,
+					},
+					"max_lease_time": {
+						Type:     schema.TypeInt,
+						Optional: true,
+						Default:  7200,
+					},
+				},
+			},
+		},
+
+		// Only used when nat is true
+		"nat_type": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "ipTranslation",
+			ValidateFunc: validation.StringInSlice([]string{
+				"ipTranslation",
+				"portForwarding",
+			}, false),
+		},
+		"nat_policy": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "allowTrafficIn",
+			ValidateFunc: validation.StringInSlice([]string{
+				"allowTrafficIn",
+				"allowTraffic",
+			}, false),
+		},
+		"nat_rule": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: vAppNetworkNatRuleSchema(),
+			},
+		},
+		"firewall_default_action": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "drop",
+			ValidateFunc: validation.StringInSlice([]string{
+				"drop",
+				"allow",
+			}, false),
+		},
+		"firewall_rule": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: vAppNetworkFirewallRuleSchema(),
+			},
+		},
+		"static_route": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: vAppNetworkStaticRouteSchema(),
+			},
+		},
+	}
+	return s
+}
+
+func vAppNetworkFirewallRuleSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"enabled": {
 			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
+		"policy": {
+			Type:     schema.TypeString,
 			Required: true,
+			ValidateFunc: validation.StringInSlice([]string{
+				"allow",
+				"drop",
+			}, false),
 		},
-		"dhcp_start": {
+		"protocol": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "any",
+			ValidateFunc: validation.StringInSlice([]string{
+				"any",
+				"tcp",
+				"udp",
+				"icmp",
+			}, true),
+		},
+		"destination_port": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Default:          "any",
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+		"destination_ip": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Default:          "any",
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+		"source_port": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Default:          "any",
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+		"source_ip": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Default:          "any",
+			DiffSuppressFunc: suppressCaseDifferences,
+		},
+		"logging": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  false,
+		},
+	}
+}
+
+func vAppNetworkNatRuleSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"vm_name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"vm_nic_id": {
+			Type:     schema.TypeInt,
+			Optional: true,
+			Default:  0,
+		},
+		// ipTranslation
+		"mapping_mode": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "automatic",
+			ValidateFunc: validation.StringInSlice([]string{
+				"automatic",
+				"manual",
+			}, false),
+		},
+		"external_ip": {
 			Type:         schema.TypeString,
 			Optional:     true,
 			ValidateFunc: ValidateIPv4(),
 		},
-		"dhcp_end": {
+		// portForwarding
+		"external_port": {
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"internal_port": {
+			Type:     schema.TypeInt,
+			Optional: true,
+		},
+		"protocol": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "TCP",
+			ValidateFunc: validation.StringInSlice([]string{
+				"TCP",
+				"UDP",
+				"TCP_UDP",
+			}, false),
+		},
+	}
+}
+
+func vAppNetworkStaticRouteSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"network": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"next_hop_ip": {
 			Type:         schema.TypeString,
-			Optional:     true,
+			Required:     true,
 			ValidateFunc: ValidateIPv4(),
 		},
+		"interface": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "External",
+			ValidateFunc: validation.StringInSlice([]string{
+				"Internal",
+				"External",
+			}, false),
+		},
 	}
-	return s
 }
 
 type VAppNetworkSubresource struct {
@@ -0,0 +1,295 @@
+package vcd
+
+import (
+	"encoding/xml"
+
+	types "github.com/vCloud/govcloudair/types/v56"
+)
+
+// The types below stand in for the govcloudair types of the same name where
+// those miss elements or only hold one of a list. They are read with
+// getEntity and sent with executeRequestWithBody, the govcloudair helpers
+// only work with their own types.
+
+// EdgeGateway represents a gateway, see types.EdgeGateway. Unlike that type
+// it can be sent back to vCD, which needs the namespace set.
+type EdgeGateway struct {
+	Xmlns string `xml:"xmlns,attr,omitempty"`
+	// Attributes
+	HREF         string `xml:"href,attr,omitempty"`         // The URI of the entity.
+	Type         string `xml:"type,attr,omitempty"`         // The MIME type of the entity.
+	ID           string `xml:"id,attr,omitempty"`           // The entity identifier, expressed in URN format.
+	OperationKey string `xml:"operationKey,attr,omitempty"` // Optional unique identifier to support idempotent semantics for create and delete operations.
+	Name         string `xml:"name,attr"`                   // The name of the entity.
+	Status       int    `xml:"status,attr,omitempty"`       // Creation status of the gateway.
+	// Elements
+	Link          types.LinkList         `xml:"Link,omitempty"`        // A link to an operation on this section.
+	Description   string                 `xml:"Description,omitempty"` // Optional description.
+	Tasks         *types.TasksInProgress `xml:"Tasks,omitempty"`       // A list of queued, running, or recently completed tasks associated with this entity.
+	Configuration *GatewayConfiguration  `xml:"Configuration"`         // Gateway configuration.
+}
+
+// GatewayConfiguration is the gateway configuration, see
+// types.GatewayConfiguration, with the syslog servers of the gateway.
+type GatewayConfiguration struct {
+	Xmlns string `xml:"xmlns,attr,omitempty"`
+	// Elements
+	BackwardCompatibilityMode       bool                        `xml:"BackwardCompatibilityMode,omitempty"`       // Compatibilty mode. Once set to true cannot be reverted back to false.
+	GatewayBackingConfig            string                      `xml:"GatewayBackingConfig"`                      // Configuration of the vShield edge VM for this gateway. One of: compact, full.
+	GatewayInterfaces               *types.GatewayInterfaces    `xml:"GatewayInterfaces"`                         // List of Gateway interfaces.
+	EdgeGatewayServiceConfiguration *GatewayFeatures            `xml:"EdgeGatewayServiceConfiguration,omitempty"` // Represents Gateway Features.
+	HaEnabled                       bool                        `xml:"HaEnabled"`                                 // True if this gateway is highly available. (Requires two vShield edge VMs.)
+	UseDefaultRouteForDNSRelay      bool                        `xml:"UseDefaultRouteForDnsRelay"`                // True if the default gateway on the external network selected for default route should be used as the DNS relay.
+	SyslogServerSettings            *types.SyslogServerSettings `xml:"SyslogServerSettings,omitempty"`            // Syslog server settings of the gateway.
+}
+
+// EdgeGatewayServiceConfiguration is the body of the configureServices action
+// of an edge gateway, see types.EdgeGatewayServiceConfiguration, which misses
+// the static routing and load balancer services.
+type EdgeGatewayServiceConfiguration struct {
+	XMLName                xml.Name                  `xml:"EdgeGatewayServiceConfiguration"`
+	Xmlns                  types.XMLNamespace        `xml:"xmlns,attr,omitempty"`
+	GatewayDhcpService     *types.GatewayDhcpService `xml:"GatewayDhcpService,omitempty"`
+	FirewallService        *FirewallService          `xml:"FirewallService,omitempty"`
+	NatService             *types.NatService         `xml:"NatService,omitempty"`
+	GatewayIpsecVpnService *GatewayIpsecVpnService   `xml:"GatewayIpsecVpnService,omitempty"` // Substitute for NetworkService. Gateway Ipsec VPN service settings
+	StaticRoutingService   *StaticRoutingService     `xml:"StaticRoutingService,omitempty"`   // Substitute for NetworkService. Static Routing service settings
+	LoadBalancerService    *LoadBalancerService      `xml:"LoadBalancerService,omitempty"`    // Substitute for NetworkService. Load Balancer service settings
+}
+
+// GatewayFeatures represents edge gateway services, see types.GatewayFeatures.
+type GatewayFeatures struct {
+	XMLName                xml.Name
+	Xmlns                  types.XMLNamespace        `xml:"xmlns,attr,omitempty"`
+	FirewallService        *FirewallService          `xml:"FirewallService,omitempty"`        // Substitute for NetworkService. Firewall service settings
+	NatService             *types.NatService         `xml:"NatService,omitempty"`             // Substitute for NetworkService. NAT service settings
+	GatewayDhcpService     *types.GatewayDhcpService `xml:"GatewayDhcpService,omitempty"`     // Substitute for NetworkService. Gateway DHCP service settings
+	GatewayIpsecVpnService *GatewayIpsecVpnService   `xml:"GatewayIpsecVpnService,omitempty"` // Substitute for NetworkService. Gateway Ipsec VPN service settings
+	LoadBalancerService    *LoadBalancerService      `xml:"LoadBalancerService,omitempty"`    // Substitute for NetworkService. Load Balancer service settings
+	StaticRoutingService   *StaticRoutingService     `xml:"StaticRoutingService,omitempty"`   // Substitute for NetworkService. Static Routing service settings
+}
+
+// StaticRoutingService represents Static Routing network service, see
+// types.StaticRoutingService, which only holds one route.
+type StaticRoutingService struct {
+	IsEnabled   bool                 `xml:"IsEnabled"`             // Enable or disable the service using this flag
+	StaticRoute []*types.StaticRoute `xml:"StaticRoute,omitempty"` // Details of each Static Route.
+}
+
+// NetworkConfigSection is the container for vApp networks, see
+// types.NetworkConfigSection. It is read from and sent to the
+// networkConfigSection link of the vApp, see getVAppNetworkConfigSection.
+type NetworkConfigSection struct {
+	XMLName xml.Name `xml:"NetworkConfigSection"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Ovf     string   `xml:"xmlns:ovf,attr,omitempty"`
+
+	Info string `xml:"ovf:Info"`
+
+	HREF          string                      `xml:"href,attr,omitempty"`
+	Type          string                      `xml:"type,attr,omitempty"`
+	Link          *types.Link                 `xml:"Link,omitempty"`
+	NetworkConfig []*VAppNetworkConfiguration `xml:"NetworkConfig,omitempty"`
+}
+
+// VAppNetworkConfiguration represents a vApp network configuration, see
+// types.VAppNetworkConfiguration.
+type VAppNetworkConfiguration struct {
+	Type        string `xml:"type,attr,omitempty"`
+	NetworkName string `xml:"networkName,attr"`
+
+	Configuration *NetworkConfiguration `xml:"Configuration"`
+	Description   string                `xml:"Description,omitempty"`
+	IsDeployed    bool                  `xml:"IsDeployed"`
+	Link          *types.Link           `xml:"Link,omitempty"`
+}
+
+// NetworkConfiguration is the configuration of a vApp network, see
+// types.NetworkConfiguration.
+type NetworkConfiguration struct {
+	BackwardCompatibilityMode      bool                        `xml:"BackwardCompatibilityMode"`
+	IPScopes                       *types.IPScopes             `xml:"IpScopes,omitempty"`
+	ParentNetwork                  *types.Reference            `xml:"ParentNetwork,omitempty"`
+	FenceMode                      types.FenceMode             `xml:"FenceMode"`
+	RetainNetInfoAcrossDeployments bool                        `xml:"RetainNetInfoAcrossDeployments"`
+	Features                       *NetworkFeatures            `xml:"Features,omitempty"`
+	RouterInfo                     *types.RouterInfo           `xml:"RouterInfo,omitempty"`
+	SyslogServerSettings           *types.SyslogServerSettings `xml:"SyslogServerSettings,omitempty"`
+	AdvancedNetworkingEnabled      bool                        `xml:"AdvancedNetworkingEnabled,omitempty"`
+	SubInterface                   bool                        `xml:"SubInterface,omitempty"`
+	DistributedInterface           bool                        `xml:"DistributedInterface,omitempty"`
+	GuestVlanAllowed               bool                        `xml:"GuestVlanAllowed,omitempty"`
+}
+
+// NetworkFeatures represents features of a vApp network, see
+// types.NetworkFeatures.
+type NetworkFeatures struct {
+	DhcpService          *types.DhcpService    `xml:"DhcpService,omitempty"`          // Substitute for NetworkService. DHCP service settings
+	FirewallService      *FirewallService      `xml:"FirewallService,omitempty"`      // Substitute for NetworkService. Firewall service settings
+	NatService           *types.NatService     `xml:"NatService,omitempty"`           // Substitute for NetworkService. NAT service settings
+	LoadBalancerService  *LoadBalancerService  `xml:"LoadBalancerService,omitempty"`  // Substitute for NetworkService. Load Balancer service settings
+	StaticRoutingService *StaticRoutingService `xml:"StaticRoutingService,omitempty"` // Substitute for NetworkService. Static Routing service settings
+}
+
+// LoadBalancerService represents gateway load balancer service, see
+// types.LoadBalancerService, which only holds one pool and virtual server.
+type LoadBalancerService struct {
+	IsEnabled     bool                         `xml:"IsEnabled"`               // Enable or disable the service using this flag
+	Pool          []*LoadBalancerPool          `xml:"Pool,omitempty"`          // List of load balancer pools.
+	VirtualServer []*LoadBalancerVirtualServer `xml:"VirtualServer,omitempty"` // List of load balancer virtual servers.
+}
+
+// LoadBalancerPool represents a load balancer pool, see
+// types.LoadBalancerPool.
+type LoadBalancerPool struct {
+	ID           string                     `xml:"Id,omitempty"`           // Load balancer pool id.
+	Name         string                     `xml:"Name"`                   // Load balancer pool name.
+	Description  string                     `xml:"Description,omitempty"`  // Load balancer pool description.
+	ServicePort  []*types.LBPoolServicePort `xml:"ServicePort"`            // Load balancer pool service port.
+	Member       []*LBPoolMember            `xml:"Member"`                 // Load balancer pool member.
+	Operational  bool                       `xml:"Operational,omitempty"`  // True if the load balancer pool is operational.
+	ErrorDetails string                     `xml:"ErrorDetails,omitempty"` // Error details for this pool.
+}
+
+// LBPoolMember represents a member in a load balancer pool, see
+// types.LBPoolMember.
+type LBPoolMember struct {
+	IPAddress   string                     `xml:"IpAddress"`             // Ip Address for load balancer member.
+	Weight      string                     `xml:"Weight"`                // Weight of this member.
+	ServicePort []*types.LBPoolServicePort `xml:"ServicePort,omitempty"` // Load balancer member service port.
+}
+
+// LoadBalancerVirtualServer represents a load balancer virtual server, see
+// types.LoadBalancerVirtualServer.
+type LoadBalancerVirtualServer struct {
+	IsEnabled             bool                                   `xml:"IsEnabled"`                       // True if this virtual server is enabled.
+	Name                  string                                 `xml:"Name"`                            // Load balancer virtual server name.
+	Description           string                                 `xml:"Description,omitempty"`           // Load balancer virtual server description.
+	Interface             *types.Reference                       `xml:"Interface"`                       // Gateway Interface to which Load Balancer Virtual Server is bound.
+	IPAddress             string                                 `xml:"IpAddress"`                       // Load balancer virtual server Ip Address.
+	ServiceProfile        []*types.LBVirtualServerServiceProfile `xml:"ServiceProfile"`                  // Load balancer virtual server service profiles.
+	Logging               bool                                   `xml:"Logging,omitempty"`               // Enable logging for this virtual server.
+	Pool                  string                                 `xml:"Pool"`                            // Name of Load balancer pool associated with this virtual server.
+	LoadBalancerTemplates *types.VendorTemplate                  `xml:"LoadBalancerTemplates,omitempty"` // Service template related attributes.
+}
+
+// FirewallService represents a network firewall service, see
+// types.FirewallService.
+type FirewallService struct {
+	IsEnabled        bool            `xml:"IsEnabled"`               // Enable or disable the service using this flag
+	DefaultAction    string          `xml:"DefaultAction,omitempty"` // Default action of the firewall. One of: drop (Default. Drop packets that match the rule.), allow (Allow packets that match the rule to pass through the firewall)
+	LogDefaultAction bool            `xml:"LogDefaultAction"`        // Flag to enable logging for default action. Default value is false.
+	FirewallRule     []*FirewallRule `xml:"FirewallRule,omitempty"`  //	A firewall rule.
+}
+
+// FirewallRule represents a firewall rule, see types.FirewallRule.
+type FirewallRule struct {
+	ID                   string                 `xml:"Id,omitempty"`                   // Firewall rule identifier.
+	IsEnabled            bool                   `xml:"IsEnabled"`                      // Used to enable or disable the firewall rule. Default value is true.
+	MatchOnTranslate     bool                   `xml:"MatchOnTranslate"`               // For DNATed traffic, match the firewall rules only after the destination IP is translated.
+	Description          string                 `xml:"Description,omitempty"`          // A description of the rule.
+	Policy               string                 `xml:"Policy,omitempty"`               // One of: drop (drop packets that match the rule), allow (allow packets that match the rule to pass through the firewall)
+	Protocols            *FirewallRuleProtocols `xml:"Protocols,omitempty"`            // Specify the protocols to which the rule should be applied.
+	IcmpSubType          string                 `xml:"IcmpSubType,omitempty"`          // ICMP subtype. One of: address-mask-request, address-mask-reply, destination-unreachable, echo-request, echo-reply, parameter-problem, redirect, router-advertisement, router-solicitation, source-quench, time-exceeded, timestamp-request, timestamp-reply, any.
+	Port                 int                    `xml:"Port,omitempty"`                 // The port to which this rule applies. A value of -1 matches any port.
+	DestinationPortRange string                 `xml:"DestinationPortRange,omitempty"` // Destination port range to which this rule applies.
+	DestinationIP        types.IPv4Address      `xml:"DestinationIp,omitempty"`        // Destination IP address to which the rule applies. A value of Any matches any IP address.
+	DestinationVM        *types.VMSelection     `xml:"DestinationVm,omitempty"`        // Details of the destination VM
+	SourcePort           int                    `xml:"SourcePort,omitempty"`           // Destination port to which this rule applies. A value of -1 matches any port.
+	SourcePortRange      string                 `xml:"SourcePortRange,omitempty"`      // Source port range to which this rule applies.
+	SourceIP             types.IPv4Address      `xml:"SourceIp,omitempty"`             // Source IP address to which the rule applies. A value of Any matches any IP address.
+	SourceVM             *types.VMSelection     `xml:"SourceVm,omitempty"`             // Details of the source Vm
+	Direction            string                 `xml:"Direction,omitempty"`            // Direction of traffic to which rule applies. One of: in (rule applies to incoming traffic. This is the default value), out (rule applies to outgoing traffic).
+	EnableLogging        bool                   `xml:"EnableLogging"`                  // Used to enable or disable firewall rule logging. Default value is false.
+}
+
+// FirewallRuleProtocols flags for a network protocol in a firewall rule, see
+// types.FirewallRuleProtocols, which misses the other protocols.
+type FirewallRuleProtocols struct {
+	ICMP  bool   `xml:"Icmp,omitempty"`  // True if the rule applies to the ICMP protocol.
+	Any   bool   `xml:"Any,omitempty"`   // True if the rule applies to any protocol.
+	TCP   bool   `xml:"Tcp,omitempty"`   // True if the rule applies to the TCP protocol.
+	UDP   bool   `xml:"Udp,omitempty"`   // True if the rule applies to the UDP protocol.
+	Other string `xml:"Other,omitempty"` // Any other protocol supported by vShield Manager
+}
+
+// GatewayIpsecVpnService represents the edge gateway IPsec VPN service, see
+// types.GatewayIpsecVpnService.
+type GatewayIpsecVpnService struct {
+	IsEnabled bool                           `xml:"IsEnabled"`          // Enable or disable the service using this flag
+	Endpoint  *types.GatewayIpsecVpnEndpoint `xml:"Endpoint,omitempty"` // List of IPSec VPN Service Endpoints.
+	Tunnel    []*GatewayIpsecVpnTunnel       `xml:"Tunnel"`             // List of IPSec VPN tunnels.
+}
+
+// GatewayIpsecVpnTunnel represents an IPsec VPN tunnel, see
+// types.GatewayIpsecVpnTunnel. A tunnel has either a third party or a local
+// peer, both are left out when not set.
+type GatewayIpsecVpnTunnel struct {
+	Name                   string                        `xml:"Name"`                             // The name of the tunnel.
+	Description            string                        `xml:"Description,omitempty"`            // A description of the tunnel.
+	IpsecVpnThirdPartyPeer *types.IpsecVpnThirdPartyPeer `xml:"IpsecVpnThirdPartyPeer,omitempty"` // Details about the peer network.
+	IpsecVpnLocalPeer      *types.IpsecVpnLocalPeer      `xml:"IpsecVpnLocalPeer,omitempty"`      // Details about the local peer network.
+	PeerIPAddress          types.IPv4Address             `xml:"PeerIpAddress"`                    // IP address of the peer endpoint.
+	PeerID                 string                        `xml:"PeerId"`                           // Id for the peer end point
+	LocalIPAddress         types.IPv4Address             `xml:"LocalIpAddress"`                   // Address of the local network.
+	LocalID                string                        `xml:"LocalId"`                          // Id for local end point
+	LocalSubnet            []*types.IpsecVpnSubnet       `xml:"LocalSubnet"`                      // List of local subnets in the tunnel.
+	PeerSubnet             []*types.IpsecVpnSubnet       `xml:"PeerSubnet"`                       // List of peer subnets in the tunnel.
+	SharedSecret           string                        `xml:"SharedSecret"`                     // Shared secret used for authentication.
+	SharedSecretEncrypted  bool                          `xml:"SharedSecretEncrypted,omitempty"`  // True if shared secret is encrypted.
+	EncryptionProtocol     string                        `xml:"EncryptionProtocol"`               // Encryption protocol to be used. One of: AES, AES256, TRIPLEDES
+	Mtu                    int                           `xml:"Mtu"`                              // MTU for the tunnel.
+	IsEnabled              bool                          `xml:"IsEnabled"`                        // True if the tunnel is enabled.
+	IsOperational          bool                          `xml:"IsOperational,omitempty"`          // True if the tunnel is operational.
+	ErrorDetails           string                        `xml:"ErrorDetails,omitempty"`           // Error details of the tunnel.
+}
+
+// StartupSection represents the ovf:StartupSection of a vApp, which holds the
+// boot order of its VMs. govcloudair has no type for it.
+type StartupSection struct {
+	XMLName xml.Name       `xml:"StartupSection"`
+	Item    []*StartupItem `xml:"Item"`
+}
+
+// StartupItem represents the startup configuration of a single VM, identified
+// by the VM name.
+type StartupItem struct {
+	ID          string `xml:"id,attr"`
+	Order       int    `xml:"order,attr"`
+	StartAction string `xml:"startAction,attr"`
+	StartDelay  int    `xml:"startDelay,attr"`
+	StopAction  string `xml:"stopAction,attr"`
+	StopDelay   int    `xml:"stopDelay,attr"`
+}
+
+// The ovf: prefixes have to be spelled out when sending the section back, but
+// prevent the attributes from being matched when decoding, hence the separate
+// types used for the request body.
+type StartupSectionRequest struct {
+	XMLName xml.Name             `xml:"ovf:StartupSection"`
+	Ovf     string               `xml:"xmlns:ovf,attr"`
+	Xmlns   string               `xml:"xmlns,attr"`
+	Info    string               `xml:"ovf:Info"`
+	Item    []StartupItemRequest `xml:"ovf:Item"`
+}
+
+type StartupItemRequest struct {
+	ID          string `xml:"ovf:id,attr"`
+	Order       int    `xml:"ovf:order,attr"`
+	StartAction string `xml:"ovf:startAction,attr"`
+	StartDelay  int    `xml:"ovf:startDelay,attr"`
+	StopAction  string `xml:"ovf:stopAction,attr"`
+	StopDelay   int    `xml:"ovf:stopDelay,attr"`
+}
+
+// LeaseSettingsSectionRequest is the body sent to change the lease settings of
+// a vApp, which are read as types.LeaseSettingsSection. Unlike that type it
+// keeps leases of 0, which never expire.
+type LeaseSettingsSectionRequest struct {
+	XMLName                  xml.Name `xml:"LeaseSettingsSection"`
+	Xmlns                    string   `xml:"xmlns,attr"`
+	Ovf                      string   `xml:"xmlns:ovf,attr"`
+	Info                     string   `xml:"ovf:Info"`
+	DeploymentLeaseInSeconds int      `xml:"DeploymentLeaseInSeconds"`
+	StorageLeaseInSeconds    int      `xml:"StorageLeaseInSeconds"`
+}